@@ -3,6 +3,8 @@ package main
 import (
 	"math/rand"
 	"strings"
+	"time"
+	"unicode"
 )
 
 type Element interface {
@@ -19,6 +21,20 @@ type Element interface {
 	Reset()
 }
 
+// Reflower is an Element that can be laid out again once the rects it
+// was given change, as they do when the window is resized. Reflow
+// redraws whatever the element had drawn on the cleared screen.
+type Reflower interface {
+	Reflow()
+}
+
+// reflow reflows elm, if it can be.
+func reflow(elm Element) {
+	if rf, ok := elm.(Reflower); ok {
+		rf.Reflow()
+	}
+}
+
 //#region WaitForInput
 
 type WaitForNext struct {
@@ -55,33 +71,96 @@ func (wfn *WaitForNext) Reset() {
 
 //#endregion WaitForInput
 
+//#region ResumePrompt
+
+// ResumePrompt waits for space like WaitForNext, and if the learner has
+// progress to go back to, offers to resume it with R.
+type ResumePrompt struct {
+	text string
+	r    *Rect
+	w    Window
+	pt   *ProgressTracker
+
+	done bool
+}
+
+func NewResumePrompt(text string, r *Rect, w Window, pt *ProgressTracker) *ResumePrompt {
+	return &ResumePrompt{text, r, w, pt, false}
+}
+
+func (rp *ResumePrompt) Update(ec []event) {
+	if rp.done {
+		return
+	}
+
+	resumable := rp.pt.CanResume()
+	if resumable {
+		DrawText(rp.text, rp.r, normal, rp.w)
+	}
+
+	if len(ec) > 0 { // if input
+		switch ev := ec[0].(type) {
+		case *keyEvent: // if key
+			switch ev.Rune() {
+			case ' ':
+				rp.done = true
+			case 'r', 'R':
+				if resumable {
+					rp.pt.Resume()
+					rp.done = true
+				}
+			}
+		}
+	}
+}
+
+func (rp *ResumePrompt) Done() bool {
+	return rp.done
+}
+
+func (rp *ResumePrompt) Reset() {
+	FillRect(' ', rp.r, rp.w)
+	rp.done = false
+}
+
+//#endregion ResumePrompt
+
 //#region SlowText
 type SlowText struct {
-	text    []rune
+	marked  *markedText
+	text    *textRun // marked's glyphs laid out in bound
+	hovs    []*PopUp // over its braces, once it is all shown
+	laidOut Rect     // bound when text and hovs were worked out
 	bound   *Rect
 	curChar int
 	w       Window
 	done    bool
-	offset  int
 
 	click     SoundEffect
 	ding      SoundEffect
-	playSound bool
+	playSound bool // until the text is skipped to its end
 }
 
-func NewSlowText(text string, bound *Rect, w Window) *SlowText {
-	return &SlowText{[]rune(text), bound, 0, w, false, 0, nil, nil, false}
+func NewSlowText(text string, bound *Rect, dict Replacer, w Window) *SlowText {
+	return NewTypewritter(text, bound, dict, w, NullSfx{}, NullSfx{})
 }
 
-func NewTypewritter(text string, bound *Rect, w Window, click SoundEffect, ding SoundEffect) *SlowText {
-	return &SlowText{[]rune(text), bound, 0, w, false, 0, click, ding, ding != nil}
+func NewTypewritter(text string, bound *Rect, dict Replacer, w Window, click SoundEffect, ding SoundEffect) *SlowText {
+	st := &SlowText{parseMarkup(text, dict), nil, nil, *bound, bound, 0, w, false, click, ding, true}
+	st.text = layoutGlyphs(st.marked.gs, bound.w, 0)
+	st.hovs = st.marked.popups(st.text, bound, w)
+	return st
 }
 
 func (st *SlowText) Update(ec []event) {
 
 	if st.done {
+		for _, pu := range st.hovs {
+			pu.Update(ec)
+		}
 		return
 	}
+	st.layout()
 
 	if len(ec) > 0 { // if input
 		switch ev := ec[0].(type) {
@@ -103,7 +182,7 @@ func (st *SlowText) Update(ec []event) {
 		}
 	}
 
-	if st.curChar >= len(st.text) {
+	if st.curChar >= len(st.text.gs) {
 		// past the last char or no more room, no more animation!
 		st.done = true
 		if st.playSound {
@@ -112,23 +191,16 @@ func (st *SlowText) Update(ec []event) {
 		return
 	}
 
-	if st.text[st.curChar] == '\t' {
-		st.offset += 3
+	g, at := st.text.gs[st.curChar], st.text.at[st.curChar]
+	// tabs and newlines just move the text along
+	if at < 0 {
 		st.curChar++
-		return
-	}
-
-	x := (st.curChar + st.offset) % st.bound.w
-	// set next cell's content
-	if st.text[st.curChar] == '\n' {
-		st.offset += st.bound.w - x - 1
-		st.curChar++
-		if st.playSound {
+		if g.mainc == '\n' && st.playSound {
 			st.ding.Play()
 		}
 		return
 	}
-	y := (st.curChar + st.offset) / st.bound.w
+	y := at / st.bound.w
 	if y >= st.bound.h {
 		st.done = true
 		if st.playSound {
@@ -140,21 +212,41 @@ func (st *SlowText) Update(ec []event) {
 	if st.playSound && rand.Float32() < 0.5 {
 		st.click.Play()
 	}
-	st.w.SetContent(st.bound.x+x, st.bound.y+y, st.text[st.curChar], normal)
+	st.w.SetContent(st.bound.x+at%st.bound.w, st.bound.y+y, g.mainc, g.combc, st.marked.styles[st.curChar])
 	st.curChar++
 }
 
+// layout works the text and its popups out again if bound has changed.
+func (st *SlowText) layout() {
+	if st.laidOut == *st.bound {
+		return
+	}
+	st.text = layoutGlyphs(st.marked.gs, st.bound.w, 0)
+	st.hovs = st.marked.popups(st.text, st.bound, st.w)
+	st.laidOut = *st.bound
+}
+
+func (st *SlowText) Reflow() {
+	for _, pu := range st.hovs {
+		pu.Reflow()
+	}
+	st.layout()
+	st.text.slice(0, st.curChar).drawStyled(st.bound, st.marked.styles[:st.curChar], st.w)
+}
+
 func (st *SlowText) Done() bool {
 	return st.done
 }
 
 func (st *SlowText) Reset() {
-	FillRect(' ', st.bound, w)
+	for _, pu := range st.hovs {
+		pu.Reset()
+	}
+	FillRect(' ', st.bound, st.w)
 
 	st.curChar = 0
 	st.done = false
-	st.offset = 0
-	st.playSound = st.click != nil
+	st.playSound = true
 }
 
 //#endregion SlowText
@@ -188,6 +280,12 @@ func (cp *ConcurrentPlayer) Done() bool {
 	return true
 }
 
+func (cp *ConcurrentPlayer) Reflow() {
+	for _, elm := range cp.elms {
+		reflow(elm)
+	}
+}
+
 func (cp *ConcurrentPlayer) Reset() {
 	for _, elm := range cp.elms {
 		elm.Reset()
@@ -202,22 +300,46 @@ type DiscretePlayer struct {
 	elms        []Element
 	curElmIndex int
 	done        bool
+
+	next      int // element to play after this one, or -1 for the one after
+	onAdvance func(index int)
+	onReset   func()
 }
 
 func NewDiscretePlayer(elms []Element) *DiscretePlayer {
-	return &DiscretePlayer{elms, 0, false}
+	return &DiscretePlayer{elms, 0, false, -1, nil, nil}
 }
 
 func (dp *DiscretePlayer) Update(ec []event) {
 
 	if dp.elms[dp.curElmIndex].Done() {
 		dp.elms[dp.curElmIndex].Reset()
-		dp.curElmIndex = (dp.curElmIndex + 1) % len(dp.elms)
+		if dp.next >= 0 {
+			dp.curElmIndex = dp.next
+			dp.next = -1
+		} else {
+			dp.curElmIndex = (dp.curElmIndex + 1) % len(dp.elms)
+		}
+		if dp.onAdvance != nil {
+			dp.onAdvance(dp.curElmIndex)
+		}
 	} else {
 		dp.elms[dp.curElmIndex].Update(ec)
 	}
 }
 
+// Seek makes index the next element played, once the current one is
+// done, instead of the one after it.
+func (dp *DiscretePlayer) Seek(index int) {
+	if 0 <= index && index < len(dp.elms) {
+		dp.next = index
+	}
+}
+
+func (dp *DiscretePlayer) Reflow() {
+	reflow(dp.elms[dp.curElmIndex])
+}
+
 func (dp *DiscretePlayer) Done() bool {
 	return dp.done
 }
@@ -225,9 +347,13 @@ func (dp *DiscretePlayer) Done() bool {
 func (dp *DiscretePlayer) Reset() {
 	dp.curElmIndex = 0
 	dp.done = false
+	dp.next = -1
 	for _, elm := range dp.elms {
 		elm.Reset()
 	}
+	if dp.onReset != nil {
+		dp.onReset()
+	}
 }
 
 //#endregion DiscretePlayer
@@ -256,6 +382,12 @@ func (sp *SequentialPlayer) Update(ec []event) {
 	}
 }
 
+func (sp *SequentialPlayer) Reflow() {
+	for elmIndex := 0; elmIndex <= sp.curElmIndex; elmIndex++ {
+		reflow(sp.elms[elmIndex])
+	}
+}
+
 //#endregion SequentialPlayer
 
 //#region Popup
@@ -292,9 +424,9 @@ func (pu *PopUp) Update(ec []event) {
 
 		pu.hovBox = &Rect{pu.mx, pu.my, pu.width, pu.height}
 		pu.repContent = CopyContent(&Rect{pu.hovBox.x - 1, pu.hovBox.y - 1, pu.hovBox.w + 2, pu.hovBox.h + 2}, pu.w)
-		FillRect(' ', pu.hovBox, w)
-		DrawBoxAround(pu.hovBox, popupBox, w)
-		DrawText(pu.text, pu.hovBox, popupBox, w)
+		FillRect(' ', pu.hovBox, pu.w)
+		DrawBoxAround(pu.hovBox, popupBox, pu.w)
+		DrawText(pu.text, pu.hovBox, popupBox, pu.w)
 		pu.showNext = false
 	}
 
@@ -319,13 +451,21 @@ func (pu *PopUp) Update(ec []event) {
 	}
 }
 
+// Reflow hides the popup without putting back what it covered, which
+// went with the screen.
+func (pu *PopUp) Reflow() {
+	pu.hovBox = nil
+	pu.repContent = nil
+	pu.showNext = false
+}
+
 func (pu *PopUp) Done() bool {
 	return true
 }
 
 func (pu *PopUp) Reset() {
 	if pu.hovBox != nil {
-		FillRect(' ', &Rect{pu.hovBox.x - 1, pu.hovBox.y - 1, pu.hovBox.w + 2, pu.hovBox.h + 2}, w)
+		FillRect(' ', &Rect{pu.hovBox.x - 1, pu.hovBox.y - 1, pu.hovBox.w + 2, pu.hovBox.h + 2}, pu.w)
 		pu.hovBox = nil
 		pu.repContent = nil
 	}
@@ -344,38 +484,39 @@ type HoverText struct {
 	// will be run through dictonary, pop up will
 	// show outputed text.
 	rawText string
+	r       *Rect
 	dict    Replacer
 	w       Window
 
 	// autogenerated
 	hovs      []*PopUp // hoverregions
 	drawCalls []drawCall
+	laidOut   Rect // r when hovs and drawCalls were worked out
 	done      bool
 }
 
+// drawCall is text laid out in rect, to be drawn with each glyph in its
+// Style from styles.
 type drawCall struct {
 	text   string
 	rect   *Rect
-	offset int
-	style  style
+	run    *textRun
+	styles []Style
 }
 
 func (dc *drawCall) Draw(w Window) {
-	DrawTextOffset(dc.text, dc.rect, dc.offset, dc.style, w)
+	dc.run.drawStyled(dc.rect, dc.styles, w)
 }
 
 func NewHoverText(text string, r *Rect, dict Replacer, w Window) *HoverText {
 	hovs, segs := HoverReplace(text, dict, r, w)
-	return &HoverText{text, dict, w, hovs, segs, false}
+	return &HoverText{text, r, dict, w, hovs, segs, *r, false}
 }
 
 func (ht *HoverText) Update(ec []event) {
+	ht.layout()
 	if !ht.done {
-		// draw all text
-		for _, htdc := range ht.drawCalls {
-			htdc.Draw(ht.w)
-		}
-
+		ht.draw()
 		ht.done = true
 	}
 
@@ -385,6 +526,31 @@ func (ht *HoverText) Update(ec []event) {
 	}
 }
 
+// layout works the text and its popups out again if r has changed.
+func (ht *HoverText) layout() {
+	if ht.laidOut == *ht.r {
+		return
+	}
+	ht.hovs, ht.drawCalls = HoverReplace(ht.rawText, ht.dict, ht.r, ht.w)
+	ht.laidOut = *ht.r
+}
+
+func (ht *HoverText) draw() {
+	for _, htdc := range ht.drawCalls {
+		htdc.Draw(ht.w)
+	}
+}
+
+func (ht *HoverText) Reflow() {
+	for _, pu := range ht.hovs {
+		pu.Reflow()
+	}
+	ht.layout()
+	if ht.done {
+		ht.draw()
+	}
+}
+
 func (ht *HoverText) Done() bool {
 	return ht.done
 }
@@ -395,7 +561,7 @@ func (ht *HoverText) Reset() {
 		pu.Reset()
 	}
 	for _, dc := range ht.drawCalls {
-		FillRect(' ', dc.rect, w)
+		FillRect(' ', dc.rect, ht.w)
 	}
 }
 
@@ -412,30 +578,51 @@ type Checker struct {
 	chk          Checkable
 	correct      []string
 	right, wrong Element
+	matcher      Matcher
 
 	state int // 0 = nothing, 1 = right input displaying, 2 = wrong input displaying
 	done  bool
+
+	attempts     int
+	wrongAnswers []string
+	rightAnswer  string
+	started      time.Time
+	took         time.Duration
+	credit       float64 // the most of any answer that was right
+	onRight      func()
+	onWrong      func()
+
+	// feedback, if set, is where a wrong answer is shown with its
+	// mistakes marked
+	feedback *Rect
+	verdict  *Verdict // the one feedback shows
+	w        Window
 }
 
 func NewChecker(chk Checkable, correct []string, right, wrong Element) *Checker {
-	thing := []string{}
-	for _, v := range correct {
-		thing = append(thing, strings.ReplaceAll(strings.ToLower(v), " ", ""))
-	}
-	return &Checker{chk, thing, right, wrong, 0, false}
+	chkr := &Checker{chk, nil, right, wrong, WordMatcher{}, 0, false, 0, nil, "", time.Time{}, 0, 0, nil, nil, nil, nil, nil}
+	chkr.setCorrect(correct)
+	return chkr
+}
+
+// setCorrect changes the answers accepted as right.
+func (chkr *Checker) setCorrect(correct []string) {
+	chkr.correct = append([]string(nil), correct...)
 }
 
 func (chkr *Checker) Update(ec []event) {
 	if chkr.done {
 		return
 	}
+	if chkr.started.IsZero() {
+		chkr.started = time.Now()
+	}
 
 	switch chkr.state {
 	case 1: // right displaying state
 		if chkr.right.Done() {
 			chkr.state = 0
 			chkr.done = true
-			w.HideCursor()
 		}
 		chkr.right.Update(ec)
 		return
@@ -456,10 +643,62 @@ func (chkr *Checker) Update(ec []event) {
 	}
 
 	// chk is done, we can actually check now!
-	if contains(chkr.correct, strings.ReplaceAll(strings.ToLower(chkr.chk.Selection()), " ", "")) {
+	answer := chkr.chk.Selection()
+	chkr.attempts++
+	v := chkr.matcher.Match(answer, chkr.correct)
+	if v.Credit > chkr.credit {
+		chkr.credit = v.Credit
+	}
+	if v.Right {
 		chkr.state = 1
+		chkr.rightAnswer = strings.TrimSpace(answer)
+		chkr.took = time.Since(chkr.started)
+		chkr.clearFeedback()
+		if chkr.onRight != nil {
+			chkr.onRight()
+		}
 	} else {
 		chkr.state = 2
+		chkr.wrongAnswers = append(chkr.wrongAnswers, strings.TrimSpace(answer))
+		if chkr.feedback != nil {
+			chkr.verdict = &v
+			drawVerdict(v, chkr.feedback, chkr.w)
+		}
+		if chkr.onWrong != nil {
+			chkr.onWrong()
+		}
+	}
+}
+
+func (chkr *Checker) clearFeedback() {
+	if chkr.feedback != nil {
+		FillRect(' ', chkr.feedback, chkr.w)
+	}
+	chkr.verdict = nil
+}
+
+func (chkr *Checker) Reflow() {
+	reflow(chkr.chk)
+	switch {
+	case chkr.state == 1 || chkr.done:
+		reflow(chkr.right)
+	case chkr.state == 2:
+		reflow(chkr.wrong)
+	}
+	if chkr.verdict != nil {
+		drawVerdict(*chkr.verdict, chkr.feedback, chkr.w)
+	}
+}
+
+// Result is how the learner has done so far; it has no ID.
+func (chkr *Checker) Result() CheckResult {
+	return CheckResult{
+		Answered: chkr.state == 1 || chkr.done,
+		Attempts: chkr.attempts,
+		Wrong:    append([]string(nil), chkr.wrongAnswers...),
+		Answer:   chkr.rightAnswer,
+		Time:     chkr.took,
+		Credit:   chkr.credit,
 	}
 }
 
@@ -477,36 +716,106 @@ func (chkr *Checker) Done() bool {
 }
 
 func (chkr *Checker) Reset() {
-	w.HideCursor()
 	chkr.chk.Reset()
 	chkr.right.Reset()
 	chkr.wrong.Reset()
 	chkr.state = 0
 	chkr.done = false
+	chkr.attempts = 0
+	chkr.wrongAnswers = nil
+	chkr.rightAnswer = ""
+	chkr.started = time.Time{}
+	chkr.took = 0
+	chkr.credit = 0
+	chkr.clearFeedback()
 }
 
 //#endregion Checker
 
+//#region ResultsSummary
+
+// ResultsSummary shows the results of a Scorecard from before lesson,
+// as they were when it was first updated.
+type ResultsSummary struct {
+	sc     *Scorecard
+	lesson int
+	r      *Rect
+	w      Window
+
+	done bool
+}
+
+func NewResultsSummary(sc *Scorecard, lesson int, r *Rect, w Window) *ResultsSummary {
+	return &ResultsSummary{sc, lesson, r, w, false}
+}
+
+func (rs *ResultsSummary) Update(ec []event) {
+	if rs.done {
+		return
+	}
+	rs.draw()
+	rs.done = true
+}
+
+func (rs *ResultsSummary) draw() {
+	for i, line := range rs.sc.before(rs.lesson).Lines() {
+		if i >= rs.r.h {
+			break
+		}
+		if l := []rune(line); len(l) > rs.r.w && rs.r.w > 3 {
+			line = string(l[:rs.r.w-3]) + "..."
+		}
+		DrawText(line, &Rect{rs.r.x, rs.r.y + i, rs.r.w, 1}, normal, rs.w)
+	}
+}
+
+func (rs *ResultsSummary) Reflow() {
+	if rs.done {
+		rs.draw()
+	}
+}
+
+func (rs *ResultsSummary) Done() bool {
+	return rs.done
+}
+
+func (rs *ResultsSummary) Reset() {
+	FillRect(' ', rs.r, rs.w)
+	rs.done = false
+}
+
+//#endregion ResultsSummary
+
 //#region Options
 
 type Options struct {
 	options []string
+	r       *Rect
 	w       Window
 
 	selected  int
 	done      bool
 	drawCalls []drawCall
+	laidOut   Rect // r when drawCalls were worked out
 }
 
 func NewOptions(options []string, r *Rect, w Window) *Options {
+	return &Options{options, r, w, 0, false, optionCalls(options, r), *r}
+}
+
+// optionCalls lays options out down r, a blank row between each, their
+// markup taken out.
+func optionCalls(options []string, r *Rect) []drawCall {
 	drawCalls := []drawCall{}
 	row := r.y
 	for _, option := range options {
-		width, height := GetDimensions(option)
-		drawCalls = append(drawCalls, drawCall{option, &Rect{r.x + 2, row, width, height}, 0, normal})
+		mt := parseMarkup(option, nil)
+		plain := mt.plain()
+		width, height := GetDimensions(plain)
+		drawCalls = append(drawCalls, drawCall{plain, &Rect{r.x + 2, row, width, height}, layoutGlyphs(mt.gs, width, 0), mt.styles})
 		row += height + 1
 	}
-	return &Options{options, w, 0, false, drawCalls}
+	return drawCalls
 }
 
 func (op *Options) Update(ec []event) {
@@ -533,6 +842,15 @@ func (op *Options) Update(ec []event) {
 		}
 	}
 
+	op.draw()
+}
+
+func (op *Options) draw() {
+	if op.laidOut != *op.r {
+		op.drawCalls = optionCalls(op.options, op.r)
+		op.laidOut = *op.r
+	}
+
 	// draw options
 	for _, dc := range op.drawCalls {
 		dc.Draw(op.w)
@@ -540,14 +858,18 @@ func (op *Options) Update(ec []event) {
 
 	// draw > <
 	selRect := op.drawCalls[op.selected].rect
-	w.SetContent(selRect.x-2, selRect.y, '>', option)
-	w.SetContent(selRect.x+selRect.w+1, selRect.y, '<', option)
+	op.w.SetContent(selRect.x-2, selRect.y, '>', nil, option.Style())
+	op.w.SetContent(selRect.x+selRect.w+1, selRect.y, '<', nil, option.Style())
+}
+
+func (op *Options) Reflow() {
+	op.draw()
 }
 
 func (op *Options) changeIndex(by int) {
 	selRect := op.drawCalls[op.selected].rect
-	w.SetContent(selRect.x-2, selRect.y, ' ', normal)
-	w.SetContent(selRect.x+selRect.w+1, selRect.y, ' ', normal)
+	op.w.SetContent(selRect.x-2, selRect.y, ' ', nil, normal.Style())
+	op.w.SetContent(selRect.x+selRect.w+1, selRect.y, ' ', nil, normal.Style())
 
 	if op.selected+by < 0 {
 		op.selected = (op.selected + by + len(op.options)) % len(op.options)
@@ -562,13 +884,13 @@ func (op *Options) Done() bool {
 
 func (op *Options) Reset() {
 	selRect := op.drawCalls[op.selected].rect
-	w.SetContent(selRect.x-2, selRect.y, ' ', normal)
-	w.SetContent(selRect.x+selRect.w+1, selRect.y, ' ', normal)
+	op.w.SetContent(selRect.x-2, selRect.y, ' ', nil, normal.Style())
+	op.w.SetContent(selRect.x+selRect.w+1, selRect.y, ' ', nil, normal.Style())
 
 	op.done = false
 	op.selected = 0
 	for _, dc := range op.drawCalls {
-		FillRect(' ', dc.rect, w)
+		FillRect(' ', dc.rect, op.w)
 	}
 }
 
@@ -576,75 +898,249 @@ func (op *Options) Selection() string {
 	if !op.done {
 		return ""
 	}
-	return op.options[op.selected]
+	return op.drawCalls[op.selected].text
 }
 
 //#endregion Options
 
 //#region UserInput
 
+// TextInput is a line of text the learner types and edits, wrapped
+// over the rows of uir and scrolled to the cursor if it outgrows them.
+// Enter finishes it.
 type TextInput struct {
 	uir      *Rect
+	w        Window
 	userText []rune
 
 	selectionReturn string
 	done            bool
-	curmx           int
+	cur             int // index in userText the cursor is before
+	max             int // most runes that can be typed, 0 for no limit
 	click, ding     SoundEffect
+
+	chars *charRow // letters to click on, if shown
 }
 
-func NewTextInput(uir *Rect) *TextInput {
-	return NewTypewritterInput(uir, nil, nil)
+func NewTextInput(uir *Rect, w Window) *TextInput {
+	return NewTypewritterInput(uir, w, nil, nil)
 }
 
-func NewTypewritterInput(uir *Rect, click, ding SoundEffect) *TextInput {
-	return &TextInput{uir, nil, "", false, 0, click, ding}
+func NewTypewritterInput(uir *Rect, w Window, click, ding SoundEffect) *TextInput {
+	return &TextInput{uir, w, nil, "", false, 0, 0, click, ding, nil}
+}
+
+// showChars shows the letters of Navajo in r, below or beside the
+// input, to be clicked on by learners whose keyboards don't have them.
+func (ti *TextInput) showChars(r *Rect) {
+	ti.chars = &charRow{navajoKeys, r, false}
 }
 
 func (ti *TextInput) Update(ec []event) {
 	if ti.done {
-		w.HideCursor()
+		ti.w.HideCursor()
 		return
 	}
 
+	if ti.chars != nil {
+		ti.chars.draw(ti.w)
+	}
+
 	if len(ec) > 0 { // if input
 		switch ev := ec[0].(type) {
 		case *keyEvent: // if key
-			ti.selectionReturn = ""
-			r := ev.Rune()
-			ti.userText = append(ti.userText, r)
-
-			if ti.click != nil {
-				ti.click.Play()
+			ti.insert(ev.Rune())
+		case *mouseEvent:
+			if ti.chars == nil {
+				break
+			}
+			if r, ok := ti.chars.hit(ev); ok {
+				ti.insert(r)
 			}
-
-			FillRect(' ', ti.uir, w)
-			DrawText(string(ti.userText), ti.uir, normal, w)
-			ti.curmx++
-			w.ShowCursor(ti.uir.x+ti.curmx, ti.uir.y)
 		case *specialEvent:
 			switch ev.Key() {
 			case backspace:
-				ti.selectionReturn = ""
-				if len(ti.userText) == 0 {
-					break
+				// the whole letter, marks and all
+				if i := ti.glyphBefore(ti.cur); i < ti.cur {
+					ti.delete(i, ti.cur)
+					ti.cur = i
 				}
-
-				ti.userText = ti.userText[:len(ti.userText)-1]
-				ti.curmx--
-
-				FillRect(' ', ti.uir, w)
-				DrawText(string(ti.userText), ti.uir, normal, w)
-				w.ShowCursor(ti.uir.x+ti.curmx, ti.uir.y)
+			case del:
+				if g, ok := ti.glyphAt(ti.cur); ok {
+					ti.delete(ti.cur, ti.cur+g)
+				}
+			case deleteWord:
+				// back over any spaces, then the word before them
+				i := ti.cur
+				for i > 0 && ti.userText[i-1] == ' ' {
+					i--
+				}
+				for i > 0 && ti.userText[i-1] != ' ' {
+					i--
+				}
+				ti.delete(i, ti.cur)
+				ti.cur = i
+			case left:
+				ti.cur = ti.glyphBefore(ti.cur)
+			case right:
+				if g, ok := ti.glyphAt(ti.cur); ok {
+					ti.cur += g
+				}
+			case up:
+				ti.moveRow(-1)
+			case down:
+				ti.moveRow(1)
+			case home:
+				ti.cur = 0
+			case end:
+				ti.cur = len(ti.userText)
 			case enter:
 				ti.selectionReturn = string(ti.userText)
 				if ti.ding != nil {
 					ti.ding.Play()
 				}
+				ti.w.HideCursor()
 				ti.done = true
+				return
+			}
+			ti.draw()
+		}
+	}
+}
+
+// insert types r at the cursor, unless the input is full.
+func (ti *TextInput) insert(r rune) {
+	if !unicode.IsGraphic(r) || (ti.max > 0 && len(ti.userText) >= ti.max) {
+		return
+	}
+	ti.selectionReturn = ""
+	ti.userText = append(ti.userText, 0)
+	copy(ti.userText[ti.cur+1:], ti.userText[ti.cur:])
+	ti.userText[ti.cur] = r
+	ti.cur++
+
+	if ti.click != nil {
+		ti.click.Play()
+	}
+	ti.draw()
+}
+
+// delete removes userText[from:to].
+func (ti *TextInput) delete(from, to int) {
+	ti.selectionReturn = ""
+	ti.userText = append(ti.userText[:from], ti.userText[to:]...)
+}
+
+// layout lays the text out in uir, returning its glyphs, the cell each
+// is drawn at, the index in userText each starts at, and the cell the
+// cursor is in.
+func (ti *TextInput) layout() (gs []glyph, at, starts []int, cursor int) {
+	run := layoutText(string(ti.userText), ti.uir.w, 0)
+	gs, at, cursor = run.gs, run.at, run.end
+	starts = make([]int, len(gs))
+	n := 0
+	for i, g := range gs {
+		starts[i] = n
+		n += 1 + len(g.combc)
+		if starts[i] <= ti.cur && ti.cur < n {
+			cursor = at[i]
+			if cursor < 0 { // a space where the row breaks
+				cursor = run.end
+				if i+1 < len(at) && at[i+1] >= 0 {
+					cursor = at[i+1]
+				}
 			}
 		}
 	}
+	return gs, at, starts, cursor
+}
+
+// glyphAt is how many runes the glyph starting at userText[i] has, if
+// one does.
+func (ti *TextInput) glyphAt(i int) (int, bool) {
+	gs, _, starts, _ := ti.layout()
+	for n, s := range starts {
+		if s == i {
+			return 1 + len(gs[n].combc), true
+		}
+	}
+	return 0, false
+}
+
+// glyphBefore is the index in userText of the glyph that ends at i, or
+// i if none does.
+func (ti *TextInput) glyphBefore(i int) int {
+	_, _, starts, _ := ti.layout()
+	for n := len(starts) - 1; n >= 0; n-- {
+		if starts[n] < i {
+			return starts[n]
+		}
+	}
+	return i
+}
+
+// moveRow moves the cursor by rows, to the glyph in the same column or
+// the last one before it.
+func (ti *TextInput) moveRow(by int) {
+	if ti.uir.w <= 0 {
+		return
+	}
+	_, at, starts, cursor := ti.layout()
+	want := cursor + by*ti.uir.w
+	if want < 0 {
+		return
+	}
+	if end := layoutText(string(ti.userText), ti.uir.w, 0).end; want >= end {
+		if by > 0 && want/ti.uir.w == end/ti.uir.w {
+			ti.cur = len(ti.userText)
+		}
+		return
+	}
+	for i := len(at) - 1; i >= 0; i-- {
+		if at[i] >= 0 && at[i] <= want {
+			ti.cur = starts[i]
+			return
+		}
+	}
+}
+
+// draw draws the text wrapped at the width of uir, from the row that
+// keeps the cursor in view, and puts the cursor in its place.
+func (ti *TextInput) draw() {
+	FillRect(' ', ti.uir, ti.w)
+	if ti.uir.w <= 0 || ti.uir.h <= 0 {
+		return
+	}
+
+	gs, at, _, cursor := ti.layout()
+	top := 0
+	if row := cursor / ti.uir.w; row >= ti.uir.h {
+		top = row - ti.uir.h + 1
+	}
+	for i, g := range gs {
+		if at[i] < 0 {
+			continue
+		}
+		row := at[i]/ti.uir.w - top
+		if row < 0 {
+			continue
+		}
+		if row >= ti.uir.h {
+			break
+		}
+		ti.w.SetContent(ti.uir.x+at[i]%ti.uir.w, ti.uir.y+row, g.mainc, g.combc, normal.Style())
+	}
+	ti.w.ShowCursor(ti.uir.x+cursor%ti.uir.w, ti.uir.y+cursor/ti.uir.w-top)
+}
+
+func (ti *TextInput) Reflow() {
+	if ti.chars != nil {
+		ti.chars.draw(ti.w)
+	}
+	ti.draw()
+	if ti.done {
+		ti.w.HideCursor()
+	}
 }
 
 func (ti *TextInput) Done() bool {
@@ -656,11 +1152,234 @@ func (ti *TextInput) Selection() string {
 }
 
 func (ti *TextInput) Reset() {
-	w.HideCursor()
-	FillRect(' ', ti.uir, w)
+	ti.w.HideCursor()
+	FillRect(' ', ti.uir, ti.w)
 	ti.userText = nil
-	ti.curmx = 0
+	ti.cur = 0
 	ti.done = false
 }
 
+// navajoKeys are the letters a charRow offers: the vowels with a high
+// tone or nasal hook, ł, the glottal stop, and a high tone to put on a
+// nasal vowel, as in į́.
+var navajoKeys = []rune("áéíóąęįǫłʼ\u0301")
+
+// charRow is a row of letters that are typed when clicked on.
+type charRow struct {
+	keys []rune
+	r    *Rect
+	held bool // a click is already being held
+}
+
+// label is how a key is shown; a combining mark on its own is shown as
+// its spacing form.
+func label(k rune) rune {
+	if k == '\u0301' {
+		return '´'
+	}
+	return k
+}
+
+func (cr *charRow) draw(w Window) {
+	for i, k := range cr.keys {
+		x := cr.r.x + i*2
+		if x >= cr.r.x+cr.r.w {
+			break
+		}
+		w.SetContent(x, cr.r.y, label(k), nil, option.Style())
+	}
+}
+
+// hit is the key clicked on by ev, if any. A held click types once.
+func (cr *charRow) hit(ev *mouseEvent) (rune, bool) {
+	if !ev.Clicked() {
+		cr.held = false
+		return 0, false
+	}
+	if cr.held {
+		return 0, false
+	}
+	cr.held = true
+
+	x, y := ev.Position()
+	i := (x - cr.r.x) / 2
+	if y != cr.r.y || x < cr.r.x || (x-cr.r.x)%2 != 0 || i >= len(cr.keys) {
+		return 0, false
+	}
+	return cr.keys[i], true
+}
+
 //#endregion UserInput
+
+//#region TranslatorPad
+
+// TranslatorPad lets the learner type English and shows it encoded
+// into code talker Navajo below, with hover hints on each code word.
+// The first line of its rect is the input, the rest is the output.
+type TranslatorPad struct {
+	tr    *Translator
+	dict  ReplaceMap
+	r     *Rect
+	input *TextInput
+	out   *Rect
+	w     Window
+
+	result *HoverText
+	done   bool
+}
+
+func NewTranslatorPad(tr *Translator, r *Rect, w Window) *TranslatorPad {
+	tp := &TranslatorPad{
+		tr, tr.ReplaceMap(), r,
+		NewTextInput(&Rect{}, w), &Rect{},
+		w, nil, false,
+	}
+	tp.layout()
+	return tp
+}
+
+// layout splits r into the input and the output.
+func (tp *TranslatorPad) layout() {
+	r := tp.r
+	*tp.input.uir = Rect{r.x, r.y, r.w, 1}
+	*tp.out = Rect{r.x, r.y + 2, r.w, r.h - 2}
+}
+
+func (tp *TranslatorPad) Update(ec []event) {
+	tp.layout()
+	if tp.result != nil {
+		tp.result.Update(ec)
+		return
+	}
+
+	tp.input.Update(ec)
+	if !tp.input.Done() {
+		return
+	}
+
+	// wrap each code word in {braces} so it gets a hover hint
+	var words []string
+	for _, word := range tp.tr.Encode(tp.input.Selection()) {
+		var nv []string
+		for _, cw := range word {
+			if cw.Type == Untranslated {
+				nv = append(nv, cw.Navajo)
+			} else {
+				nv = append(nv, "{"+cw.Navajo+"}")
+			}
+		}
+		words = append(words, strings.Join(nv, " "))
+	}
+
+	tp.result = NewHoverText(strings.Join(words, " / "), tp.out, tp.dict, tp.w)
+	tp.result.Update(nil)
+	tp.done = true
+}
+
+func (tp *TranslatorPad) Reflow() {
+	tp.layout()
+	tp.input.Reflow()
+	if tp.result != nil {
+		tp.result.Reflow()
+	}
+}
+
+func (tp *TranslatorPad) Done() bool {
+	return tp.done
+}
+
+func (tp *TranslatorPad) Reset() {
+	tp.input.Reset()
+	if tp.result != nil {
+		tp.result.Reset()
+		tp.result = nil
+	}
+	tp.done = false
+}
+
+//#endregion TranslatorPad
+
+//#region Quiz
+
+// Quiz asks the learner to decode a question picked at random, or to
+// encode it if encode is set, checked like a Checker, and picks another
+// each time it is reset. The question is drawn at the top of its rect
+// and answered on the last line.
+type Quiz struct {
+	tr        *Translator
+	questions []string
+	hints     QuizHints
+	encode    bool
+	rnd       *rand.Rand
+	r         *Rect
+	qr        *Rect // the question's part of r
+	w         Window
+
+	asked    int // index of the question being asked
+	question *HoverText
+	input    *TextInput
+	chkr     *Checker
+}
+
+func NewQuiz(tr *Translator, questions []string, hints QuizHints, encode bool, r *Rect, w Window, right, wrong Element, rnd *rand.Rand) *Quiz {
+	input := NewTextInput(&Rect{}, w)
+	q := &Quiz{
+		tr, questions, hints, encode, rnd,
+		r, &Rect{}, w,
+		-1, nil, input, NewChecker(input, nil, right, wrong),
+	}
+	q.layout()
+	q.ask()
+	return q
+}
+
+// layout splits r into the question and, on its last line, the input.
+func (q *Quiz) layout() {
+	r := q.r
+	*q.qr = Rect{r.x, r.y, r.w, r.h - 2}
+	*q.input.uir = Rect{r.x, r.y + r.h - 1, r.w, 1}
+}
+
+// ask picks a question, a different one to last time if it can.
+func (q *Quiz) ask() {
+	i := q.rnd.Intn(len(q.questions))
+	for len(q.questions) > 1 && i == q.asked {
+		i = q.rnd.Intn(len(q.questions))
+	}
+	q.asked = i
+
+	rm := ReplaceMap{}
+	var text string
+	if q.encode {
+		text = quizEncodeText(q.tr.Encode(q.questions[i]), q.hints, rm)
+	} else {
+		text = quizHintText(q.tr.Encode(q.questions[i]), q.hints, rm)
+	}
+	q.question = NewHoverText(text, q.qr, rm, q.w)
+	q.chkr.setCorrect([]string{q.questions[i]})
+}
+
+func (q *Quiz) Update(ec []event) {
+	q.layout()
+	q.question.Update(ec)
+	q.chkr.Update(ec)
+}
+
+func (q *Quiz) Reflow() {
+	q.layout()
+	q.question.Reflow()
+	q.chkr.Reflow()
+}
+
+func (q *Quiz) Done() bool {
+	return q.chkr.Done()
+}
+
+func (q *Quiz) Reset() {
+	q.question.Reset()
+	FillRect(' ', q.qr, q.w)
+	q.chkr.Reset()
+	q.ask()
+}
+
+//#endregion Quiz
@@ -0,0 +1,119 @@
+package main
+
+import "testing"
+
+// keys is text typed, as events.
+func keys(text string) []event {
+	var evs []event
+	for _, r := range text {
+		evs = append(evs, &keyEvent{r})
+	}
+	return evs
+}
+
+func TestTextInputEditing(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		width int
+		max   int
+		evs   [][]event
+		text  string
+		cur   int
+	}{
+		{"typed", 20, 0, [][]event{keys("ab c")}, "ab c", 4},
+		{"backspace", 20, 0, [][]event{keys("abc"), {&specialEvent{backspace}}}, "ab", 2},
+		{"backspace at the start", 20, 0, [][]event{keys("ab"), {&specialEvent{home}, &specialEvent{backspace}}}, "ab", 0},
+		// a letter with marks is deleted whole
+		{"backspace a letter", 20, 0, [][]event{keys("ai\u0328\u0301"), {&specialEvent{backspace}}}, "a", 1},
+		{"backspace inside", 20, 0, [][]event{keys("ai\u0328b"), {&specialEvent{left}, &specialEvent{backspace}}}, "ab", 1},
+		{"delete", 20, 0, [][]event{keys("ab"), {&specialEvent{home}, &specialEvent{del}}}, "b", 0},
+		{"delete a letter", 20, 0, [][]event{keys("i\u0301\u0328a"), {&specialEvent{home}, &specialEvent{del}}}, "a", 0},
+		{"delete at the end", 20, 0, [][]event{keys("ab"), {&specialEvent{del}}}, "ab", 2},
+		{"left over a letter", 20, 0, [][]event{keys("ao\u0328"), {&specialEvent{left}}}, "ao\u0328", 1},
+		{"right over a letter", 20, 0, [][]event{keys("o\u0328a"), {&specialEvent{home}, &specialEvent{right}}}, "o\u0328a", 2},
+		{"insert", 20, 0, [][]event{keys("ac"), {&specialEvent{left}}, keys("b")}, "abc", 2},
+		{"delete word", 20, 0, [][]event{keys("one two  "), {&specialEvent{deleteWord}}}, "one ", 4},
+		{"max", 20, 3, [][]event{keys("abcd")}, "abc", 3},
+		{"end", 20, 0, [][]event{keys("abc"), {&specialEvent{home}, &specialEvent{end}}}, "abc", 3},
+		{"down a row", 4, 0, [][]event{keys("ab cd ef"), {&specialEvent{home}, &specialEvent{right}, &specialEvent{down}}}, "ab cd ef", 4},
+		{"up a row", 4, 0, [][]event{keys("ab cd ef"), {&specialEvent{up}}}, "ab cd ef", 5},
+		{"down past the end", 4, 0, [][]event{keys("ab cd ef"), {&specialEvent{home}, &specialEvent{down}, &specialEvent{down}, &specialEvent{down}}}, "ab cd ef", 6},
+		{"up at the top", 4, 0, [][]event{keys("ab cd"), {&specialEvent{home}, &specialEvent{right}, &specialEvent{up}}}, "ab cd", 1},
+		// laid out before it has any room
+		{"no room", 0, 0, [][]event{keys("ab"), {&specialEvent{up}, &specialEvent{down}, &specialEvent{left}}}, "ab", 1},
+	} {
+		w := NewHeadlessWindow(20, 4)
+		ti := NewTextInput(&Rect{0, 0, tt.width, 3}, w)
+		ti.max = tt.max
+		for _, evs := range tt.evs {
+			for _, ev := range evs {
+				ti.Update([]event{ev})
+			}
+		}
+		if string(ti.userText) != tt.text || ti.cur != tt.cur {
+			t.Errorf("%s: text %q, cursor %d, want %q, %d", tt.name, string(ti.userText), ti.cur, tt.text, tt.cur)
+		}
+	}
+}
+
+func TestTextInputEnter(t *testing.T) {
+	w := NewHeadlessWindow(20, 4)
+	ti := NewTextInput(&Rect{0, 0, 20, 1}, w)
+	for _, ev := range append(keys("shash"), &specialEvent{enter}) {
+		ti.Update([]event{ev})
+	}
+	if !ti.Done() || ti.Selection() != "shash" {
+		t.Errorf("after enter: done %v, selection %q", ti.Done(), ti.Selection())
+	}
+
+	ti.Reset()
+	if ti.Done() || len(ti.userText) != 0 || ti.cur != 0 {
+		t.Errorf("after reset: done %v, text %q, cursor %d", ti.Done(), string(ti.userText), ti.cur)
+	}
+}
+
+func TestResultsSummary(t *testing.T) {
+	sc := &Scorecard{}
+	sc.add("type1", 1)
+	sc.add("type2", 1)
+	sc.add("final", 2)
+	sc.record(CheckResult{ID: "type1", Lesson: 1, Answered: true, Attempts: 2, Wrong: []string{"apple"}})
+
+	for _, tt := range []struct {
+		name string
+		r    *Rect
+		want string
+	}{
+		// final is in the lesson the summary is in, so isn't shown
+		{"all of it", &Rect{1, 1, 49, 4}, ` Question     Attempts  Time   Wrong answers` + "\n" +
+			` type1        2         0:00   "apple"` + "\n" +
+			` type2        -         -` + "\n" +
+			` Score: 2/6` + "\n\n"},
+		// rows that don't fit are cut off, and the score left out
+		{"small", &Rect{0, 2, 20, 2}, "\n" +
+			"Question     Atte...\n" +
+			"type1        2   ...\n" +
+			"\n\n"},
+	} {
+		w := NewHeadlessWindow(50, 6)
+		dr := w.GetDrawingRect()
+		rs := NewResultsSummary(sc, 2, tt.r, w)
+		rs.Update(nil)
+		if got := w.DumpRect(dr); got != tt.want || !rs.Done() {
+			t.Errorf("%s: drew\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+
+		// answers given since aren't shown until it is reset
+		sc.record(CheckResult{ID: "type2", Lesson: 1, Answered: true, Attempts: 1})
+		rs.Update(nil)
+		if got := w.DumpRect(dr); got != tt.want {
+			t.Errorf("%s: drew again\n%s", tt.name, got)
+		}
+		sc.record(CheckResult{ID: "type2", Lesson: 1})
+
+		rs.Reset()
+		if got := w.DumpRect(dr); got != "\n\n\n\n\n" || rs.Done() {
+			t.Errorf("%s: after reset, drew\n%s", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+// box is how many cells across and down something takes.
+type box struct{ w, h int }
+
+// sizer is how big something is when it can be at most maxW wide.
+type sizer func(maxW int) box
+
+// Arrangement gives rects to things of the sizes given, in order, within
+// an area, so that they don't overlap however long their text is. Things
+// of no size take no room, nor any gap beside them.
+type Arrangement interface {
+	Arrange(area Rect, sizes []sizer) []Rect
+}
+
+// arrangements are the Arrangements a scene can lay a player out with.
+var arrangements = map[string]func(gap int) Arrangement{
+	"stack":  func(gap int) Arrangement { return Stack{gap} },
+	"center": func(gap int) Arrangement { return Center{gap} },
+	"flow":   func(gap int) Arrangement { return Flow{gap} },
+}
+
+// Stack puts things one under another down the area, Gap blank rows
+// apart, each as wide as the area.
+type Stack struct{ Gap int }
+
+func (s Stack) Arrange(area Rect, sizes []sizer) []Rect {
+	rs := make([]Rect, len(sizes))
+	y := area.y
+	for i, size := range sizes {
+		b := size(area.w)
+		rs[i] = Rect{area.x, y, area.w, b.h}
+		if b != (box{}) {
+			y += b.h + s.Gap
+		}
+	}
+	return rs
+}
+
+// Center stacks things like Stack, but each only as wide as it needs
+// and centered across the area, with the stack centered down it.
+type Center struct{ Gap int }
+
+func (c Center) Arrange(area Rect, sizes []sizer) []Rect {
+	boxes := make([]box, len(sizes))
+	total, shown := 0, 0
+	for i, size := range sizes {
+		boxes[i] = fit(size, area.w)
+		if boxes[i] == (box{}) {
+			continue
+		}
+		if shown > 0 {
+			total += c.Gap
+		}
+		total += boxes[i].h
+		shown++
+	}
+
+	rs := make([]Rect, len(sizes))
+	y := area.y
+	if total < area.h {
+		y += (area.h - total) / 2
+	}
+	for i, b := range boxes {
+		rs[i] = Rect{area.x + (area.w-b.w)/2, y, b.w, b.h}
+		if b != (box{}) {
+			y += b.h + c.Gap
+		}
+	}
+	return rs
+}
+
+// Flow puts things side by side across the area, Gap blank columns
+// apart, starting a new row, Gap blank rows below the tallest of the
+// last, when one doesn't fit.
+type Flow struct{ Gap int }
+
+func (f Flow) Arrange(area Rect, sizes []sizer) []Rect {
+	rs := make([]Rect, len(sizes))
+	x, y, rowH := area.x, area.y, 0
+	for i, size := range sizes {
+		b := fit(size, area.w)
+		if b == (box{}) {
+			rs[i] = Rect{x, y, 0, 0}
+			continue
+		}
+		if x > area.x && x+b.w > area.x+area.w {
+			x, y, rowH = area.x, y+rowH+f.Gap, 0
+		}
+		rs[i] = Rect{x, y, b.w, b.h}
+		x += b.w + f.Gap
+		if b.h > rowH {
+			rowH = b.h
+		}
+	}
+	return rs
+}
+
+// fit is the size of something at most maxW wide, even if size gives
+// it more room than that.
+func fit(size sizer, maxW int) box {
+	b := size(maxW)
+	if b.w > maxW {
+		b.w = maxW
+	}
+	return b
+}
+
+// textSize is the size of text drawn no wider than it needs to be, and
+// wrapped if that's wider than maxW. Its markup isn't counted.
+func textSize(text string) sizer {
+	text = stripMarkup(text)
+	return func(maxW int) box {
+		w, _ := GetDimensions(text)
+		if w > maxW {
+			w = maxW
+		}
+		return box{w, layoutText(text, w, 0).rows()}
+	}
+}
+
+// optionsSize is the size of Options, which are drawn one under another
+// a row apart, with room either side of each for the > < around it.
+// Options aren't wrapped, so one too long for maxW runs past it.
+func optionsSize(options []string) sizer {
+	return func(maxW int) box {
+		var b box
+		for i, option := range options {
+			w, h := GetDimensions(stripMarkup(option))
+			if w+4 > b.w {
+				b.w = w + 4
+			}
+			if i > 0 {
+				b.h++
+			}
+			b.h += h
+		}
+		if b.w > maxW {
+			b.w = maxW
+		}
+		return b
+	}
+}
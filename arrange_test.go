@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fixed is something w by h, or as wide as it may be if that's less.
+func fixed(w, h int) sizer {
+	return func(maxW int) box {
+		if w > maxW {
+			w = maxW
+		}
+		return box{w, h}
+	}
+}
+
+// unbounded is something w by h however wide it may be, like a sizer
+// that doesn't keep to maxW.
+func unbounded(w, h int) sizer {
+	return func(int) box { return box{w, h} }
+}
+
+func TestArrangements(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		arr   Arrangement
+		area  Rect
+		sizes []sizer
+		want  []Rect
+	}{
+		{"stack", Stack{1}, Rect{2, 3, 20, 10},
+			[]sizer{fixed(5, 2), fixed(0, 0), fixed(3, 1)},
+			[]Rect{{2, 3, 20, 2}, {2, 6, 20, 0}, {2, 6, 20, 1}}},
+		{"stack past the bottom", Stack{0}, Rect{0, 0, 10, 2},
+			[]sizer{fixed(5, 2), fixed(5, 2)},
+			[]Rect{{0, 0, 10, 2}, {0, 2, 10, 2}}},
+		{"center", Center{1}, Rect{0, 0, 20, 10},
+			[]sizer{fixed(4, 2), fixed(0, 0), fixed(6, 1)},
+			[]Rect{{8, 3, 4, 2}, {10, 6, 0, 0}, {7, 6, 6, 1}}},
+		{"center too tall", Center{0}, Rect{0, 1, 20, 10},
+			[]sizer{fixed(4, 6), fixed(4, 6)},
+			[]Rect{{8, 1, 4, 6}, {8, 7, 4, 6}}},
+		{"center too wide", Center{0}, Rect{0, 0, 10, 10},
+			[]sizer{fixed(30, 2)},
+			[]Rect{{0, 4, 10, 2}}},
+		{"center unbounded", Center{0}, Rect{2, 0, 11, 9},
+			[]sizer{unbounded(27, 1), unbounded(5, 1)},
+			[]Rect{{2, 3, 11, 1}, {5, 4, 5, 1}}},
+		{"flow", Flow{1}, Rect{0, 0, 10, 10},
+			[]sizer{fixed(4, 1), fixed(0, 0), fixed(4, 2), fixed(4, 1)},
+			[]Rect{{0, 0, 4, 1}, {5, 0, 0, 0}, {5, 0, 4, 2}, {0, 3, 4, 1}}},
+		{"flow too wide", Flow{1}, Rect{1, 0, 10, 10},
+			[]sizer{fixed(3, 1), fixed(20, 1)},
+			[]Rect{{1, 0, 3, 1}, {1, 2, 10, 1}}},
+		{"flow unbounded", Flow{1}, Rect{0, 0, 10, 10},
+			[]sizer{unbounded(20, 1), unbounded(3, 1)},
+			[]Rect{{0, 0, 10, 1}, {0, 2, 3, 1}}},
+		{"nothing", Flow{1}, Rect{0, 0, 10, 10}, nil, []Rect{}},
+	} {
+		if got := tt.arr.Arrange(tt.area, tt.sizes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Arrange = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextSize(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		maxW int
+		want box
+	}{
+		{"hello there world", 100, box{17, 1}},
+		{"hello there world", 11, box{11, 2}},
+		{"hello there world", 5, box{5, 3}},
+		{"ab\ncdef", 100, box{4, 2}},
+		{"", 100, box{0, 0}},
+	} {
+		if got := textSize(tt.text)(tt.maxW); got != tt.want {
+			t.Errorf("textSize(%q)(%d) = %v, want %v", tt.text, tt.maxW, got, tt.want)
+		}
+	}
+}
+
+func TestOptionsSize(t *testing.T) {
+	for maxW, want := range map[int]box{100: {10, 5}, 10: {10, 5}, 7: {7, 5}} {
+		if got := optionsSize([]string{"yes", "no", "longer"})(maxW); got != want {
+			t.Errorf("optionsSize(%d) = %v, want %v", maxW, got, want)
+		}
+	}
+}
+
+func TestTextRunRows(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		w    int
+		want int
+	}{
+		{"ab cd ef", 4, 3},
+		{"abcd", 4, 1},
+		{"abcd\n", 4, 1},
+		{"", 4, 0},
+		{"ab", 0, 0},
+	} {
+		if got := layoutText(tt.text, tt.w, 0).rows(); got != tt.want {
+			t.Errorf("layoutText(%q, %d).rows() = %d, want %d", tt.text, tt.w, got, tt.want)
+		}
+	}
+}
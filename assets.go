@@ -0,0 +1,41 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// builtin holds the sounds, scenes and dictionaries the tutorial ships
+// with, so the binary runs from anywhere.
+//
+//go:embed assets/*.wav scenes/*.json dict/*.tsv dict/*.txt
+var builtin embed.FS
+
+var assetsFlag = flag.String("assets", "", "directory whose files override the built-in assets, e.g. . for this repository")
+
+// readFile reads a scene, dictionary or sound. A file of the same name
+// in the -assets directory wins over the built-in one, and paths that
+// are neither are read from the file system as they are.
+func readFile(name string) ([]byte, error) {
+	if *assetsFlag != "" && !filepath.IsAbs(name) {
+		b, err := os.ReadFile(filepath.Join(*assetsFlag, name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+
+	if p := path.Clean(filepath.ToSlash(name)); fs.ValidPath(p) {
+		if b, err := builtin.ReadFile(p); err == nil {
+			return b, nil
+		}
+	}
+
+	return os.ReadFile(name)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// commands are subcommands run instead of the tutorial, e.g.
+// `nct translate ask company b`.
+var commands = map[string]func(args []string) error{
+	"translate": translateCmd,
+	"replay":    replayCmd,
+	"summary":   summaryCmd,
+}
+
+// translateCmd encodes English into Navajo code, or decodes it with -d.
+// Letters with several Type 1 words take each in turn, or one at random
+// with -random.
+// The text is taken from the arguments, or from stdin line by line if
+// there are none.
+func translateCmd(args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	decode := fs.Bool("d", false, "decode Navajo code into English")
+	random := fs.Bool("random", false, "spell each letter with one of its Type 1 words at random, rather than each in turn")
+	fs.Parse(args)
+
+	if *random {
+		defaultTranslator.Randomise(rand.New(rand.NewSource(time.Now().UnixNano())))
+	}
+
+	translate := func(text string, out io.Writer) {
+		if !*decode {
+			fmt.Fprintln(out, defaultTranslator.EncodeString(text))
+			return
+		}
+
+		d := defaultTranslator.Decode(text)
+		fmt.Fprintln(out, d.Text)
+		for _, a := range d.Ambiguities {
+			if len(a.Options) == 0 {
+				fmt.Fprintf(os.Stderr, "word %d: unknown word %q\n", a.Pos+1, a.Navajo)
+				continue
+			}
+			var opts []string
+			for _, cw := range a.Options {
+				opts = append(opts, cw.English)
+			}
+			fmt.Fprintf(os.Stderr, "word %d: %q is ambiguous (%s)\n", a.Pos+1, a.Navajo, strings.Join(opts, ", "))
+		}
+	}
+
+	if fs.NArg() > 0 {
+		translate(strings.Join(fs.Args(), " "), os.Stdout)
+		return nil
+	}
+
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		translate(sc.Text(), os.Stdout)
+	}
+	return sc.Err()
+}
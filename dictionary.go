@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DictEntry is one word of the code talker vocabulary. English is the
+// letter a Type 1 word spells, or the term a Type 2 word stands for.
+// Literal is what the Navajo word means on its own, e.g. "ant" for
+// wóláchííʼ; Notes are for the dictionary's authors only.
+type DictEntry struct {
+	Type    CodeType
+	English string
+	Navajo  string
+	Literal string
+	Notes   string
+}
+
+// Dictionary is the code talker vocabulary, with every word held once.
+type Dictionary struct {
+	Entries []DictEntry
+}
+
+// dictColumns are the columns of a dictionary file, in order.
+var dictColumns = []string{"type", "english", "navajo", "literal", "notes"}
+
+// LoadDictionary reads a dictionary file. It is tab separated, with a
+// header row naming dictColumns and lines starting with # ignored.
+func LoadDictionary(path string) (*Dictionary, error) {
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := ReadDictionary(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return d, nil
+}
+
+// ReadDictionary reads a dictionary in the LoadDictionary format.
+func ReadDictionary(r io.Reader) (*Dictionary, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	if len(header) < 3 || header[0] != "type" || header[1] != "english" || header[2] != "navajo" {
+		return nil, fmt.Errorf("header must be %s", strings.Join(dictColumns, "\t"))
+	}
+
+	d := &Dictionary{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		for len(rec) < len(dictColumns) {
+			rec = append(rec, "")
+		}
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+
+		de := DictEntry{English: rec[1], Navajo: rec[2], Literal: rec[3], Notes: rec[4]}
+		switch rec[0] {
+		case "1":
+			de.Type = Type1
+			if len([]rune(de.English)) != 1 {
+				return nil, fmt.Errorf("line %d: Type 1 word must spell a single letter, not %q", line, de.English)
+			}
+		case "2":
+			de.Type = Type2
+		default:
+			return nil, fmt.Errorf("line %d: type must be 1 or 2, not %q", line, rec[0])
+		}
+		if de.English == "" || de.Navajo == "" {
+			return nil, fmt.Errorf("line %d: english and navajo are required", line)
+		}
+
+		d.Entries = append(d.Entries, de)
+	}
+
+	return d, nil
+}
+
+// ReplaceMap generates every direction of the dictionary for HoverText:
+//
+//   - a letter to all of its Type 1 words (t1ln)
+//   - the literal meaning of a Type 1 word to the word (t1en)
+//   - a Type 1 word to its literal meaning (t1ne)
+//   - a Type 2 word to its term and literal meaning, and the term and
+//     literal meaning to the word (t2ne)
+//
+// The first entry wins where two would generate the same key.
+func (d *Dictionary) ReplaceMap() ReplaceMap {
+	rm := ReplaceMap{}
+	add := func(key string, tr translation) {
+		key = strings.ToLower(key)
+		if _, ok := rm[key]; !ok && key != "" {
+			rm[key] = tr
+		}
+	}
+
+	letters := map[string][]string{}
+	var order []string
+	for _, de := range d.Entries {
+		switch de.Type {
+		case Type1:
+			l := strings.ToLower(de.English)
+			if _, ok := letters[l]; !ok {
+				order = append(order, l)
+			}
+			letters[l] = append(letters[l], de.Navajo+" ("+de.Literal+")")
+			add(de.Literal, translation{de.Navajo, t1en})
+			add(de.Navajo, translation{de.Literal, t1ne})
+		case Type2:
+			meaning := de.English
+			if de.Literal != "" {
+				meaning += "\n\"" + de.Literal + "\""
+			}
+			add(de.Navajo, translation{meaning, t2ne})
+			add(de.English, translation{de.Navajo, t2ne})
+			add(de.Literal, translation{de.Navajo, t2ne})
+		}
+	}
+	for _, l := range order {
+		add(l, translation{strings.Join(letters[l], "\n"), t1ln})
+	}
+
+	return rm
+}
+
+// Translator creates a Translator for the dictionary.
+func (d *Dictionary) Translator() *Translator {
+	return NewTranslator(d.Entries)
+}
+
+// UseDictionary makes d the master dictionary, used by scenes and the
+// default Translator.
+func UseDictionary(d *Dictionary) {
+	master = d.ReplaceMap()
+	dictionaries["master"] = master
+	defaultTranslator = d.Translator()
+}
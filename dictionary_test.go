@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDictionary(t *testing.T) {
+	d, err := ReadDictionary(strings.NewReader(
+		"# a comment\n" +
+			"type\tenglish\tnavajo\tliteral\tnotes\n" +
+			"1\tA\twóláchííʼ\tant\n" +
+			"# another\n" +
+			"2\t submarine \tbéésh łóóʼ\tiron fish\tboats\n" +
+			"2\tcome\thohkááh\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DictEntry{
+		{Type1, "A", "wóláchííʼ", "ant", ""},
+		{Type2, "submarine", "béésh łóóʼ", "iron fish", "boats"},
+		{Type2, "come", "hohkááh", "", ""},
+	}
+	if !reflect.DeepEqual(d.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", d.Entries, want)
+	}
+}
+
+func TestReadDictionaryErrors(t *testing.T) {
+	const header = "type\tenglish\tnavajo\n"
+	for _, tt := range []struct {
+		dict, err string
+	}{
+		{"", "reading header"},
+		{"english\tnavajo\n", "header must be"},
+		{header + "1\tab\tshash\n", "line 2: Type 1 word must spell a single letter"},
+		{header + "3\tb\tshash\n", "line 2: type must be 1 or 2"},
+		{header + "1\tb\tshash\n2\tcome\t\n", "line 3: english and navajo are required"},
+		{header + "2\t\thohkááh\n", "line 2: english and navajo are required"},
+	} {
+		_, err := ReadDictionary(strings.NewReader(tt.dict))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ReadDictionary(%q) error = %v, want %q", tt.dict, err, tt.err)
+		}
+	}
+}
+
+func TestDictionaryReplaceMap(t *testing.T) {
+	d, err := ReadDictionary(strings.NewReader(testDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm := d.ReplaceMap()
+	for _, tt := range []struct {
+		key, text string
+		s         style
+	}{
+		{"a", "wóláchííʼ (ant)\nbe-la-sana (apple)", t1ln},
+		{"ant", "wóláchííʼ", t1en},
+		{"Wóláchííʼ", "ant", t1ne},
+		{"béésh łóóʼ", "submarine\n\"iron fish\"", t2ne},
+		{"submarine", "béésh łóóʼ", t2ne},
+		{"iron fish", "béésh łóóʼ", t2ne},
+		{"fire", "beeʼeldǫǫh", t2ne},
+		{"nothing", "", normal},
+	} {
+		if text, s := rm.getText(tt.key), rm.getColor(tt.key); text != tt.text || s != tt.s {
+			t.Errorf("ReplaceMap[%q] = %q, %v, want %q, %v", tt.key, text, s, tt.text, tt.s)
+		}
+	}
+}
+
+func TestMasterDictionary(t *testing.T) {
+	d, err := LoadDictionary("dict/master.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	letters := map[string]bool{}
+	for _, de := range d.Entries {
+		if de.Type == Type1 {
+			letters[strings.ToLower(de.English)] = true
+		}
+	}
+	if len(letters) != 26 {
+		t.Errorf("master dictionary spells %d letters, want 26", len(letters))
+	}
+}
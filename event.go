@@ -2,12 +2,28 @@ package main
 
 type event interface{}
 
-type mouseEvent struct{ mx, my int }
+// mouseEvent is the mouse moving, or clicking if click is set. A held
+// button clicks for as long as it's held.
+type mouseEvent struct {
+	mx, my int
+	click  bool
+}
 
 func (me *mouseEvent) Position() (int, int) {
 	return me.mx, me.my
 }
 
+func (me *mouseEvent) Clicked() bool {
+	return me.click
+}
+
+// resizeEvent is the window changing size to width by height cells.
+type resizeEvent struct{ width, height int }
+
+func (re *resizeEvent) Size() (int, int) {
+	return re.width, re.height
+}
+
 type keyEvent struct{ key rune }
 
 func (ke *keyEvent) Rune() rune {
@@ -31,4 +47,8 @@ const (
 	enter
 	quit
 	reset
+	del        // delete the rune after the cursor
+	home       // to the start of the text
+	end        // to the end of the text
+	deleteWord // delete the word before the cursor
 )
@@ -5,6 +5,8 @@ go 1.18
 require (
 	github.com/Ahoys123/tcell v0.0.0-20230102213152-a87126020b33
 	github.com/faiface/beep v1.1.0
+	github.com/mattn/go-runewidth v0.0.14
+	github.com/rivo/uniseg v0.2.0
 )
 
 require (
@@ -12,9 +14,7 @@ require (
 	github.com/gdamore/tcell/v2 v2.5.4 // indirect
 	github.com/hajimehoshi/oto v0.7.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.14 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/rivo/uniseg v0.2.0 // indirect
 	golang.org/x/exp v0.0.0-20190306152737-a1d7652674e8 // indirect
 	golang.org/x/image v0.0.0-20190227222117-0694c2d4d067 // indirect
 	golang.org/x/mobile v0.0.0-20190415191353-3e0bab5405d6 // indirect
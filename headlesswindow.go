@@ -0,0 +1,205 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// HeadlessWindow is a Window that draws into memory instead of onto a
+// screen, so scenes can be run and inspected without a terminal. Events
+// are fed to it with Send, and Dump shows what has been drawn.
+type HeadlessWindow struct {
+	cells         [][]pixel
+	DrawableRect  *Rect
+	width, height int
+	minW, minH    int // the size it was made, below which it is too small
+
+	cursorX, cursorY int
+	cursorShown      bool
+	frames           int
+	finished         bool
+
+	evChan chan event
+	quit   chan struct{}
+}
+
+// headlessEventBuffer is how many events Send can queue before the
+// scene has read them.
+const headlessEventBuffer = 256
+
+func NewHeadlessWindow(width, height int) *HeadlessWindow {
+	w := &HeadlessWindow{
+		blankCells(width, height), &Rect{0, 1, width, height - 1}, width, height, width, height,
+		0, 0, false, 0, false,
+		make(chan event, headlessEventBuffer), make(chan struct{}),
+	}
+
+	DrawOverlay(w)
+
+	return w
+}
+
+func blankCells(width, height int) [][]pixel {
+	cells := make([][]pixel, height)
+	for y := range cells {
+		cells[y] = make([]pixel, width)
+		for x := range cells[y] {
+			cells[y][x] = pixel{' ', nil, normal.Style()}
+		}
+	}
+	return cells
+}
+
+// ResolutionCheck changes the window's size to width by height, as if a
+// terminal had been resized, and then checks it like TermWindow's does,
+// against the size the window was made.
+func (w *HeadlessWindow) ResolutionCheck(width, height int, old *VirtualRegion) *VirtualRegion {
+	cells := blankCells(width, height)
+	for y := 0; y < height && y < w.height; y++ {
+		copy(cells[y], w.cells[y])
+	}
+	w.cells, w.width, w.height = cells, width, height
+
+	if width < w.minW || height < w.minH {
+		if old != nil {
+			return old
+		}
+		vr := CopyContent(w.DrawableRect, w)
+		w.cells = blankCells(width, height)
+		DrawResolutionWarning(w.minW, w.minH, w)
+		return &vr
+	}
+
+	dr := Rect{0, 1, width, height - 1}
+	if old == nil && dr == *w.DrawableRect {
+		return nil
+	}
+	w.cells = blankCells(width, height)
+	DrawOverlay(w)
+	*w.DrawableRect = dr
+	return nil
+}
+
+func (w *HeadlessWindow) SetContent(x, y int, mainc rune, combc []rune, s Style) {
+	if !(0 <= x && x < w.width && 0 <= y && y < w.height) {
+		return
+	}
+	w.cells[y][x] = pixel{mainc, combc, s}
+}
+
+func (w *HeadlessWindow) GetContent(x, y int) (rune, []rune, Style) {
+	if !(0 <= x && x < w.width && 0 <= y && y < w.height) {
+		return ' ', nil, Style{}
+	}
+	px := w.cells[y][x]
+	return px.mainc, px.combc, px.s
+}
+
+// ChannelEvents returns the channel Send writes to.
+func (w *HeadlessWindow) ChannelEvents() (chan event, chan struct{}) {
+	return w.evChan, w.quit
+}
+
+// Send queues an event as if it came from the user.
+func (w *HeadlessWindow) Send(e event) {
+	w.evChan <- e
+}
+
+// Type sends a key event for every rune of text.
+func (w *HeadlessWindow) Type(text string) {
+	for _, r := range text {
+		w.Send(&keyEvent{r})
+	}
+}
+
+func (w *HeadlessWindow) GetDrawingRect() *Rect {
+	return w.DrawableRect
+}
+func (w *HeadlessWindow) GetWidth() int  { return w.width }
+func (w *HeadlessWindow) GetHeight() int { return w.height }
+
+func (w *HeadlessWindow) HideCursor() {
+	w.cursorShown = false
+}
+
+func (w *HeadlessWindow) ShowCursor(x, y int) {
+	w.cursorX, w.cursorY = x, y
+	w.cursorShown = true
+}
+
+// Cursor returns where the cursor is, and if it is shown.
+func (w *HeadlessWindow) Cursor() (x, y int, shown bool) {
+	return w.cursorX, w.cursorY, w.cursorShown
+}
+
+// Show counts frames; there is nothing to flush.
+func (w *HeadlessWindow) Show() {
+	w.frames++
+}
+
+// Frames returns how many times Show has been called.
+func (w *HeadlessWindow) Frames() int {
+	return w.frames
+}
+
+func (w *HeadlessWindow) Fini() {
+	w.finished = true
+}
+
+// Finished reports if Fini has been called.
+func (w *HeadlessWindow) Finished() bool {
+	return w.finished
+}
+
+func (w *HeadlessWindow) Sync() {}
+
+// Dump returns the window's contents as text, one line per row with
+// trailing spaces trimmed.
+func (w *HeadlessWindow) Dump() string {
+	return w.DumpRect(&Rect{0, 0, w.width, w.height})
+}
+
+// DumpRect returns the contents of r as text, like Dump. The cell after
+// a wide character is left out, as the character covers it.
+func (w *HeadlessWindow) DumpRect(r *Rect) string {
+	var sb strings.Builder
+	for y := r.y; y < r.y+r.h; y++ {
+		var line []rune
+		for x := 0; x < r.w; x++ {
+			mainc, combc, _ := w.GetContent(r.x+x, y)
+			line = append(append(line, mainc), combc...)
+			if runewidth.RuneWidth(mainc) == 2 {
+				x++
+			}
+		}
+		sb.WriteString(strings.TrimRight(string(line), " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// DumpStyles returns the style of every cell in the window, one line
+// per row. Normal cells are blank and every other style is its number
+// in the theme, or * if it isn't one of them, such as an underlined
+// hover word, so it lines up with Dump.
+func (w *HeadlessWindow) DumpStyles() string {
+	var sb strings.Builder
+	for y := 0; y < w.height; y++ {
+		line := make([]rune, w.width)
+		for x := range line {
+			_, _, st := w.GetContent(x, y)
+			switch s, ok := theme.styleOf(st); {
+			case !ok:
+				line[x] = '*'
+			case s != normal:
+				line[x] = rune('0' + s)
+			default:
+				line[x] = ' '
+			}
+		}
+		sb.WriteString(strings.TrimRight(string(line), " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata instead of comparing with them")
+
+func TestHeadlessWindowDump(t *testing.T) {
+	w := NewHeadlessWindow(12, 3)
+	DrawText("ná水b", &Rect{1, 1, 10, 1}, normal, w)
+	w.SetContent(1, 2, 'a', []rune{'\u0328', '\u0301'}, normal.Style())
+
+	// the cell a wide character covers is left out, and trailing
+	// spaces trimmed
+	if got, want := w.DumpRect(&Rect{0, 1, 12, 2}), " ná水b\n a\u0328\u0301\n"; got != want {
+		t.Errorf("DumpRect(rows 1 and 2) = %q, want %q", got, want)
+	}
+	if got, want := w.DumpRect(&Rect{2, 1, 4, 2}), "á水b\n\n"; got != want {
+		t.Errorf("DumpRect(2, 1, 4, 2) = %q, want %q", got, want)
+	}
+	if got := w.Dump(); !strings.HasSuffix(got, w.DumpRect(&Rect{0, 1, 12, 2})) || strings.Count(got, "\n") != 3 {
+		t.Errorf("Dump() = %q, want the overlay row then rows 1 and 2", got)
+	}
+
+	// drawing off the window is ignored, and reads back blank
+	w.SetContent(-1, 0, 'x', nil, option.Style())
+	w.SetContent(12, 2, 'x', nil, option.Style())
+	if mainc, _, s := w.GetContent(12, 2); mainc != ' ' || s != (Style{}) {
+		t.Errorf("GetContent off the window = %q, %+v", mainc, s)
+	}
+}
+
+func TestHeadlessWindowDumpStyles(t *testing.T) {
+	w := NewHeadlessWindow(20, 2)
+	FillRect(' ', &Rect{0, 0, 20, 1}, w)
+	w.SetContent(0, 1, 'a', nil, t1ne.Style())
+	w.SetContent(2, 1, 'b', nil, t2ne.Style().Underline())
+	if got, want := w.DumpStyles(), "\n6 *\n"; got != want {
+		t.Errorf("DumpStyles() = %q, want %q", got, want)
+	}
+}
+
+// golden plays script against the element newElm builds, on a width by
+// height HeadlessWindow, failing t on any check in it that fails. Each
+// "snapshot NAME" in script compares the window's Dump with the golden
+// file testdata/NAME.txt, or rewrites it with -update.
+func golden(t *testing.T, width, height int, newElm func(w Window) Element, script string) {
+	t.Helper()
+	w := NewHeadlessWindow(width, height)
+	rp := NewReplay(newElm(w), w, "testdata")
+	rp.Update = *update
+	if err := rp.Run(t.Name(), strings.NewReader(script)); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range rp.Failures {
+		t.Error(f)
+	}
+}
+
+func testReplaceMap(t *testing.T) ReplaceMap {
+	t.Helper()
+	d, err := ReadDictionary(strings.NewReader(testDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.ReplaceMap()
+}
+
+func TestGoldenElements(t *testing.T) {
+	rm := testReplaceMap(t)
+	for _, tt := range []struct {
+		name   string
+		newElm func(w Window) Element
+		script string
+	}{
+		{"slowtext", func(w Window) Element {
+			return NewSlowText("The quick brown fox jumps over the lazy dog.\n\tIndented.", &Rect{1, 2, 20, 4}, nil, w)
+		}, `
+			tick 5
+			snapshot slowtext-typing
+			tick 100
+			snapshot slowtext-typed
+		`},
+		{"slowtext-skip", func(w Window) Element {
+			return NewSlowText("Pressing space shows all of the text at once.", &Rect{1, 2, 20, 4}, nil, w)
+		}, `
+			tick 2
+			key space
+			snapshot slowtext-skipped
+		`},
+		{"slowtext-markup", func(w Window) Element {
+			return NewSlowText("Hover over [b]{shash}[/b] or {this|a tip}.", &Rect{1, 2, 30, 2}, rm, w)
+		}, `
+			tick 100
+			expect Hover over shash or this.
+			reject [b]
+			mouse 13 2
+			tick
+			snapshot slowtext-hover
+			mouse 22 2
+			tick
+			snapshot slowtext-hover-tip
+		`},
+		{"hovertext", func(w Window) Element {
+			return NewHoverText("What does {shash} {wóláchííʼ} {tsah} spell?", &Rect{1, 2, 30, 2}, rm, w)
+		}, `
+			tick
+			snapshot hovertext
+			mouse 12 2
+			tick
+			snapshot hovertext-hover
+			mouse 0 7
+			tick
+			reject bear
+		`},
+		{"options", func(w Window) Element {
+			return NewOptions([]string{"yes", "no", "a longer option"}, &Rect{2, 2, 20, 5}, w)
+		}, `
+			tick
+			snapshot options
+			key down
+			key down
+			snapshot options-down
+			key down
+			snapshot options-wrapped
+		`},
+		{"checker", func(w Window) Element {
+			return NewChecker(NewTextInput(&Rect{1, 2, 20, 1}, w), []string{"banana"},
+				NewSlowText("Good Job!", &Rect{1, 4, 20, 1}, nil, w),
+				NewSequentialPlayer([]Element{NewSlowText("Try again!", &Rect{1, 4, 20, 1}, nil, w), NewWaitForNext()}))
+		}, `
+			type bandana
+			key enter
+			tick 20
+			snapshot checker-wrong
+			key space
+			tick
+			reject Try again!
+			type banana
+			key enter
+			tick 20
+			snapshot checker-right
+		`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			golden(t, 40, 8, tt.newElm, tt.script)
+		})
+	}
+}
+
+// TestGoldenLessons plays the first lessons of the scene the tutorial
+// ships with.
+func TestGoldenLessons(t *testing.T) {
+	d, err := LoadDictionary("dict/master.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseDictionary(d)
+
+	golden(t, 79, 20, func(w Window) Element {
+		scene, err := LoadScene("scenes/main.json", w, silentSfx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scene
+	}, `
+		tick 100
+		snapshot lesson-title
+		key space
+		tick 500
+		snapshot lesson-welcome
+		key space
+		tick 500
+		snapshot lesson-0
+		key space
+		tick 800
+		key space
+		tick 800
+		expect LESSON 2:    TYPE 1 CODE
+		type banana
+		key enter
+		tick 50
+		snapshot lesson-2-right
+	`)
+}
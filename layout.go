@@ -0,0 +1,225 @@
+package main
+
+import (
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// tabWidth is how many cells a tab moves the text along.
+const tabWidth = 4
+
+// glyph is one grapheme cluster of text as it is drawn: its first rune,
+// the runes combined with it, like the accents stacked on į́, and how
+// many cells it takes. Tabs and newlines are glyphs of no width, moving
+// the text along rather than being drawn.
+type glyph struct {
+	mainc rune
+	combc []rune
+	width int
+}
+
+// glyphs splits text into glyphs.
+func glyphs(text string) []glyph {
+	var gs []glyph
+	gr := uniseg.NewGraphemes(text)
+	for gr.Next() {
+		rs := gr.Runes()
+		g := glyph{rs[0], nil, 0}
+		switch {
+		case rs[0] == '\r' && len(rs) > 1: // \r\n is one cluster
+			g.mainc = '\n'
+		case rs[0] == '\n' || rs[0] == '\t':
+		default:
+			g.width = runewidth.RuneWidth(rs[0])
+			if g.width == 0 { // a mark with nothing to go on
+				g.width = 1
+			}
+			if len(rs) > 1 {
+				g.combc = rs[1:]
+			}
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
+
+// textRun is text laid out in rows w cells wide: its glyphs, and the
+// cell each is drawn at, where the cell x, y from the top left of the
+// rows is at%w, at/w. Tabs, newlines and a space where a row breaks
+// aren't drawn, and are at -1.
+type textRun struct {
+	gs  []glyph
+	at  []int
+	w   int
+	end int // the cell after the last glyph
+}
+
+// layoutText lays text out in rows w cells wide, as if offset cells of
+// it had been laid out already.
+func layoutText(text string, w, offset int) *textRun {
+	return layoutGlyphs(glyphs(text), w, offset)
+}
+
+// layoutGlyphs lays gs out in rows w cells wide from cell offset. Rows
+// are broken between words, and words too long for a row are broken
+// wherever they reach its edge. A newline starts a new row and a tab
+// moves tabWidth cells along.
+func layoutGlyphs(gs []glyph, w, offset int) *textRun {
+	tr := &textRun{gs, make([]int, len(gs)), w, offset}
+	if w <= 0 {
+		for i := range tr.at {
+			tr.at[i] = -1
+		}
+		return tr
+	}
+
+	pos := offset
+	filled := false // the last row ended by being filled
+	for i := 0; i < len(gs); {
+		x := pos % w
+		switch gs[i].mainc {
+		case '\n':
+			tr.at[i] = -1
+			if !filled {
+				pos += w - x
+			}
+			filled = false
+			i++
+			continue
+		case '\t':
+			tr.at[i] = -1
+			pos += tabWidth
+			filled = false
+			i++
+			continue
+		case ' ':
+			// a space the row ended at isn't carried onto the next
+			if filled {
+				tr.at[i] = -1
+			} else {
+				tr.at[i] = pos
+				pos++
+				filled = pos%w == 0
+			}
+			i++
+			continue
+		}
+
+		// a word goes on the next row if it doesn't fit on this one
+		// but would on a row of its own
+		j, width := i, 0
+		for ; j < len(gs) && !breaks(gs[j]); j++ {
+			width += gs[j].width
+		}
+		if x != 0 && x+width > w && width <= w {
+			pos += w - x
+		}
+		for ; i < j; i++ {
+			tr.at[i], pos = place(gs[i], pos, w)
+		}
+		filled = pos%w == 0
+	}
+	tr.end = pos
+	return tr
+}
+
+// breaks reports whether a row can be broken at g.
+func breaks(g glyph) bool {
+	return g.mainc == ' ' || g.mainc == '\n' || g.mainc == '\t'
+}
+
+// place puts g at cell pos of rows w wide, or at the start of the next
+// row if it is too wide for what is left of this one. It returns the
+// cell g is drawn at and the cell after it.
+func place(g glyph, pos, w int) (at, next int) {
+	if x := pos % w; x+g.width > w && x != 0 {
+		pos += w - x
+	}
+	return pos, pos + g.width
+}
+
+// slice is the glyphs i to j of the run, where they were laid out.
+func (tr *textRun) slice(i, j int) *textRun {
+	end := tr.end
+	if j < len(tr.gs) {
+		end = tr.at[j]
+	}
+	if end < 0 { // the next glyph isn't drawn
+		end = tr.end
+		for k := j - 1; k >= i; k-- {
+			if tr.at[k] >= 0 {
+				end = tr.at[k] + tr.gs[k].width
+				break
+			}
+		}
+	}
+	return &textRun{tr.gs[i:j], tr.at[i:j], tr.w, end}
+}
+
+// rows is how many rows the run takes.
+func (tr *textRun) rows() int {
+	if tr.w <= 0 {
+		return 0
+	}
+	return (tr.end + tr.w - 1) / tr.w
+}
+
+// draw draws the run in r, which should be as wide as the run's rows,
+// leaving out whatever is below r.
+func (tr *textRun) draw(r *Rect, s Style, w Window) {
+	tr.drawWith(r, w, func(int) Style { return s })
+}
+
+// drawStyled draws the run like draw, each glyph in its own Style from
+// styles.
+func (tr *textRun) drawStyled(r *Rect, styles []Style, w Window) {
+	tr.drawWith(r, w, func(i int) Style { return styles[i] })
+}
+
+func (tr *textRun) drawWith(r *Rect, w Window, style func(i int) Style) {
+	for i, g := range tr.gs {
+		if tr.at[i] < 0 {
+			continue
+		}
+		y := tr.at[i] / tr.w
+		if y >= r.h {
+			return
+		}
+		w.SetContent(r.x+tr.at[i]%tr.w, r.y+y, g.mainc, g.combc, style(i))
+	}
+}
+
+// rects are the rects the run covers drawn in r, one for each row.
+func (tr *textRun) rects(r *Rect) (rs []*Rect) {
+	var last *Rect
+	for i, g := range tr.gs {
+		if tr.at[i] < 0 {
+			continue
+		}
+		x, y := tr.at[i]%tr.w, tr.at[i]/tr.w
+		if y >= r.h {
+			break
+		}
+		if last == nil || last.y != r.y+y {
+			last = &Rect{r.x + x, r.y + y, 0, 1}
+			rs = append(rs, last)
+		}
+		last.w = r.x + x + g.width - last.x
+	}
+	return rs
+}
+
+// textWidth is how many cells a line of text takes.
+func textWidth(text string) int {
+	n := 0
+	for _, g := range glyphs(text) {
+		switch g.mainc {
+		case '\t':
+			n += tabWidth
+		case '\n':
+		default:
+			n += g.width
+		}
+	}
+	return n
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlyphs(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want []glyph
+	}{
+		{"", nil},
+		{"ab", []glyph{{'a', nil, 1}, {'b', nil, 1}}},
+		{"i\u0328\u0301z", []glyph{{'i', []rune{'\u0328', '\u0301'}, 1}, {'z', nil, 1}}},
+		{"水b", []glyph{{'水', nil, 2}, {'b', nil, 1}}},
+		{"a\r\nb\nc", []glyph{{'a', nil, 1}, {'\n', nil, 0}, {'b', nil, 1}, {'\n', nil, 0}, {'c', nil, 1}}},
+		{"\ta", []glyph{{'\t', nil, 0}, {'a', nil, 1}}},
+		// a mark with nothing to go on still takes a cell
+		{"\u0301", []glyph{{'\u0301', nil, 1}}},
+	} {
+		if got := glyphs(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("glyphs(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	for _, tt := range []struct {
+		width, pos, w int
+		at, next      int
+	}{
+		{1, 3, 4, 3, 4},
+		{2, 2, 4, 2, 4},
+		// a wide glyph that doesn't fit goes on the next row
+		{2, 3, 4, 4, 6},
+		{2, 7, 4, 8, 10},
+		// unless it would fit on no row
+		{5, 0, 4, 0, 5},
+	} {
+		at, next := place(glyph{'x', nil, tt.width}, tt.pos, tt.w)
+		if at != tt.at || next != tt.next {
+			t.Errorf("place(width %d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.pos, tt.w, at, next, tt.at, tt.next)
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	for text, want := range map[string]int{
+		"":               0,
+		"abc":            3,
+		"水b":             3,
+		"a\tb":           2 + tabWidth,
+		"a\nb":           2,
+		"i\u0328\u0301z": 2,
+	} {
+		if got := textWidth(text); got != want {
+			t.Errorf("textWidth(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestLayoutText(t *testing.T) {
+	for _, tt := range []struct {
+		text      string
+		w, offset int
+		at        []int
+		end       int
+	}{
+		{"ab cd", 10, 0, []int{0, 1, 2, 3, 4}, 5},
+		// words that don't fit go on the next row
+		{"ab cd ef", 4, 0, []int{0, 1, 2, 4, 5, 6, 8, 9}, 10},
+		{"ab cd", 4, 3, []int{4, 5, 6, 8, 9}, 10},
+		// the space a filled row ends at isn't drawn, and a newline
+		// there doesn't leave a blank row
+		{"abcd efgh", 4, 0, []int{0, 1, 2, 3, -1, 4, 5, 6, 7}, 8},
+		{"abcd\nef", 4, 0, []int{0, 1, 2, 3, -1, 4, 5}, 6},
+		{"abc d", 4, 0, []int{0, 1, 2, 3, 4}, 5},
+		{"abc  d", 4, 0, []int{0, 1, 2, 3, -1, 4}, 5},
+		{"abc \nd", 4, 0, []int{0, 1, 2, 3, -1, 4}, 5},
+		{"abcd\n\nef", 4, 0, []int{0, 1, 2, 3, -1, -1, 8, 9}, 10},
+		// words too long for any row are broken at its edge
+		{"abcdefghij", 4, 0, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 10},
+		{"a bcdefgh", 4, 0, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}, 9},
+		{"a水", 2, 0, []int{0, 2}, 4},
+		{"a\nb", 4, 0, []int{0, -1, 4}, 5},
+		{"\tb", 8, 0, []int{-1, tabWidth}, tabWidth + 1},
+		{"ab", 0, 3, []int{-1, -1}, 3},
+	} {
+		tr := layoutText(tt.text, tt.w, tt.offset)
+		if !reflect.DeepEqual(tr.at, tt.at) || tr.end != tt.end {
+			t.Errorf("layoutText(%q, %d, %d) = %v, %d, want %v, %d", tt.text, tt.w, tt.offset, tr.at, tr.end, tt.at, tt.end)
+		}
+	}
+}
+
+func TestTextRunSlice(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		i, j int
+		at   []int
+		end  int
+	}{
+		{"ab cd ef", 0, 2, []int{0, 1}, 2},
+		{"ab cd ef", 3, 5, []int{4, 5}, 6},
+		{"ab cd ef", 6, 8, []int{8, 9}, 10},
+		// ending before a glyph that isn't drawn
+		{"abcd efgh", 0, 4, []int{0, 1, 2, 3}, 4},
+		{"a\nb", 0, 1, []int{0}, 1},
+	} {
+		s := layoutText(tt.text, 4, 0).slice(tt.i, tt.j)
+		if !reflect.DeepEqual(s.at, tt.at) || s.end != tt.end || s.w != 4 {
+			t.Errorf("%q slice(%d, %d) = %v, %d, want %v, %d", tt.text, tt.i, tt.j, s.at, s.end, tt.at, tt.end)
+		}
+	}
+}
+
+func TestTextRunRects(t *testing.T) {
+	tr := layoutText("ab cd ef", 4, 0)
+	got := tr.rects(&Rect{10, 5, 4, 2})
+	want := []*Rect{{10, 5, 3, 1}, {10, 6, 3, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rects = %v, want %v", got, want)
+	}
+
+	if got := layoutText("ab", 4, 2).rects(&Rect{0, 0, 4, 1}); !reflect.DeepEqual(got, []*Rect{{2, 0, 2, 1}}) {
+		t.Errorf("rects from an offset = %v", got)
+	}
+}
+
+func TestTextRunDraw(t *testing.T) {
+	w := NewHeadlessWindow(6, 3)
+	layoutText("ab cd ef gh", 5, 0).draw(&Rect{1, 1, 5, 2}, normal.Style(), w)
+	if got, want := w.DumpRect(&Rect{0, 1, 6, 2}), " ab cd\n ef gh\n"; got != want {
+		t.Errorf("draw = %q, want %q", got, want)
+	}
+}
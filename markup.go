@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Text drawn by SlowText, HoverText and Options can be styled and
+// annotated inline:
+//
+//	[b]bold[/b], [i]italic[/i] and [u]underlined[/u]
+//	[color=t2ne]drawn as t2ne is in the theme[/color]
+//	{word}          shows what the element's dictionary has for word
+//	{words|tooltip} shows tooltip
+//
+// Words in braces are underlined and show a popup when hovered over. A
+// word the dictionary has is drawn in its style, anything else in the
+// style of the markup around it. Options, picked with the keys, show no
+// popups. Tags can be nested but not put inside braces, and anything
+// else in square brackets, like [SPACE], is drawn as it is.
+
+// markedText is text with its markup taken out: its glyphs, the Style
+// each is drawn in and the parts to hover over.
+type markedText struct {
+	gs     []glyph
+	styles []Style
+	hovers []hover
+}
+
+// hover is glyphs from to to of a markedText, which show tip when
+// hovered over.
+type hover struct {
+	from, to int
+	tip      string
+}
+
+// markup is the styling open at a point in marked up text.
+type markup struct {
+	colors                  []style
+	bold, italic, underline int
+}
+
+// style is how text is drawn under m, in c rather than m's colour.
+func (m *markup) style(c style) Style {
+	s := c.Style()
+	if m.bold > 0 {
+		s = s.Bold()
+	}
+	if m.italic > 0 {
+		s = s.Italic()
+	}
+	if m.underline > 0 {
+		s = s.Underline()
+	}
+	return s
+}
+
+// color is m's colour, normal outside any [color] tag.
+func (m *markup) color() style {
+	if len(m.colors) == 0 {
+		return normal
+	}
+	return m.colors[len(m.colors)-1]
+}
+
+// tag applies tag, the text between a pair of square brackets, to m,
+// reporting whether it was a tag.
+func (m *markup) tag(tag string) bool {
+	shut := func(n *int) {
+		if *n > 0 {
+			*n--
+		}
+	}
+	switch tag {
+	case "b":
+		m.bold++
+	case "/b":
+		shut(&m.bold)
+	case "i":
+		m.italic++
+	case "/i":
+		shut(&m.italic)
+	case "u":
+		m.underline++
+	case "/u":
+		shut(&m.underline)
+	case "/color":
+		if len(m.colors) > 0 {
+			m.colors = m.colors[:len(m.colors)-1]
+		}
+	default:
+		name := strings.TrimPrefix(tag, "color=")
+		s, ok := parseStyle(name)
+		if name == tag || !ok {
+			return false
+		}
+		m.colors = append(m.colors, s)
+	}
+	return true
+}
+
+// parseMarkup takes the markup out of text, looking words in braces
+// without a tooltip up in rplcr, which can be nil.
+func parseMarkup(text string, rplcr Replacer) *markedText {
+	if rplcr == nil {
+		rplcr = ReplaceMap{}
+	}
+	mt := &markedText{}
+	add := func(s string, st Style) {
+		for _, g := range glyphs(s) {
+			mt.gs = append(mt.gs, g)
+			mt.styles = append(mt.styles, st)
+		}
+	}
+
+	var m markup
+	plain := 0 // where the text not yet added starts
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '[':
+			end := strings.IndexByte(text[i:], ']')
+			next := m
+			if end < 0 || !next.tag(text[i+1:i+end]) {
+				continue
+			}
+			add(text[plain:i], m.style(m.color()))
+			m = next
+			i += end
+			plain = i + 1
+
+		case '{':
+			add(text[plain:i], m.style(m.color()))
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 { // the rest is in braces
+				end = len(text) - i
+			}
+			word, tip, ok := strings.Cut(text[i+1:i+end], "|")
+			c := m.color()
+			if !ok {
+				tip = rplcr.getText(word)
+				if dc := rplcr.getColor(word); dc != normal {
+					c = dc
+				}
+			}
+			from := len(mt.gs)
+			add(word, m.style(c).Underline())
+			mt.hovers = append(mt.hovers, hover{from, len(mt.gs), tip})
+			i += end
+			plain = i + 1
+		}
+	}
+	if plain < len(text) {
+		add(text[plain:], m.style(m.color()))
+	}
+	return mt
+}
+
+// plain is the text without its markup.
+func (mt *markedText) plain() string {
+	var b strings.Builder
+	for _, g := range mt.gs {
+		b.WriteRune(g.mainc)
+		for _, r := range g.combc {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// stripMarkup is text as it is drawn, without its markup.
+func stripMarkup(text string) string {
+	return parseMarkup(text, nil).plain()
+}
+
+// popups are popups over each hover of mt, whose glyphs are laid out as
+// run in r.
+func (mt *markedText) popups(run *textRun, r *Rect, w Window) (pus []*PopUp) {
+	for _, h := range mt.hovers {
+		width, height := GetDimensions(h.tip)
+		for _, v := range run.slice(h.from, h.to).rects(r) {
+			pus = append(pus, NewPopUp(h.tip, width, height, v, w))
+		}
+	}
+	return pus
+}
+
+// checkMarkup reports [color] tags in text naming styles there aren't,
+// which would otherwise be drawn as they are.
+func checkMarkup(text string) error {
+	for rest := text; ; {
+		i := strings.Index(rest, "[color=")
+		if i < 0 {
+			return nil
+		}
+		rest = rest[i+len("[color="):]
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return nil
+		}
+		if _, ok := parseStyle(rest[:end]); !ok {
+			return fmt.Errorf("unknown style %q in [color]", rest[:end])
+		}
+	}
+}
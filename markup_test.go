@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// styledRun is text drawn in one Style.
+type styledRun struct {
+	text string
+	s    Style
+}
+
+// styledRuns are mt's glyphs, run together where they are drawn alike.
+func styledRuns(mt *markedText) []styledRun {
+	var runs []styledRun
+	for i, g := range mt.gs {
+		text := string(append([]rune{g.mainc}, g.combc...))
+		if n := len(runs); n > 0 && runs[n-1].s == mt.styles[i] {
+			runs[n-1].text += text
+			continue
+		}
+		runs = append(runs, styledRun{text, mt.styles[i]})
+	}
+	return runs
+}
+
+func TestParseMarkup(t *testing.T) {
+	rm := testReplaceMap(t)
+	n, t1, t2 := normal.Style(), t1ne.Style(), t2ne.Style()
+	for _, tt := range []struct {
+		text   string
+		runs   []styledRun
+		hovers []hover
+	}{
+		{"plain", []styledRun{{"plain", n}}, nil},
+		{"a [b]bold[/b] c", []styledRun{{"a ", n}, {"bold", n.Bold()}, {" c", n}}, nil},
+		{"[u]a[/u][i]b[/i]", []styledRun{{"a", n.Underline()}, {"b", n.Italic()}}, nil},
+		// tags needn't close in the order they opened
+		{"[b][i]x[/b]y[/i]z", []styledRun{{"x", n.Bold().Italic()}, {"y", n.Italic()}, {"z", n}}, nil},
+		{"[b][b]x[/b]y[/b]z", []styledRun{{"xy", n.Bold()}, {"z", n}}, nil},
+		{"[color=t2ne]a[color=t1ne]b[/color]c[/color]d", []styledRun{{"a", t2}, {"b", t1}, {"c", t2}, {"d", n}}, nil},
+		{"[b][color=t1ne]x", []styledRun{{"x", t1.Bold()}}, nil},
+		// anything else in brackets is drawn as it is
+		{"Press [SPACE] or [color=nope]x [b", []styledRun{{"Press [SPACE] or [color=nope]x [b", n}}, nil},
+		{"[/b][/color]x", []styledRun{{"x", n}}, nil},
+		{"a } b", []styledRun{{"a } b", n}}, nil},
+		// a word the dictionary has is drawn in its style
+		{"{shash} and {this|a tip}", []styledRun{{"shash", t1.Underline()}, {" and ", n}, {"this", n.Underline()}},
+			[]hover{{0, 5, rm.getText("shash")}, {10, 14, "a tip"}}},
+		{"[b]{this|tip}[/b]", []styledRun{{"this", n.Bold().Underline()}}, []hover{{0, 4, "tip"}}},
+		{"[color=t2ne]{xyz}[/color]", []styledRun{{"xyz", t2.Underline()}}, []hover{{0, 3, ""}}},
+		{"{two words|a tip}", []styledRun{{"two words", n.Underline()}}, []hover{{0, 9, "a tip"}}},
+		// the rest is in braces
+		{"a {unclosed", []styledRun{{"a ", n}, {"unclosed", n.Underline()}}, []hover{{2, 10, ""}}},
+	} {
+		mt := parseMarkup(tt.text, rm)
+		if got := styledRuns(mt); !reflect.DeepEqual(got, tt.runs) {
+			t.Errorf("parseMarkup(%q) drawn as %+v, want %+v", tt.text, got, tt.runs)
+		}
+		if !reflect.DeepEqual(mt.hovers, tt.hovers) {
+			t.Errorf("parseMarkup(%q) hovers = %+v, want %+v", tt.text, mt.hovers, tt.hovers)
+		}
+	}
+}
+
+func TestStripMarkup(t *testing.T) {
+	for text, want := range map[string]string{
+		"":                                "",
+		"[b]Hi[/b] {shash|x} [SPACE]":     "Hi shash [SPACE]",
+		"[color=t1ne]{wóláchííʼ}[/color]": "wóláchííʼ",
+		"a\n[i]b[/i]":                     "a\nb",
+	} {
+		if got := stripMarkup(text); got != want {
+			t.Errorf("stripMarkup(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestCheckMarkup(t *testing.T) {
+	for text, want := range map[string]string{
+		"no tags":                            "",
+		"[color=t1ne]x[/color] [color=t2ne]": "",
+		"[color=":                            "",
+		"[SPACE] [color=blue]x":              `unknown style "blue" in [color]`,
+		"[color=t1ne]a[color=]b":             `unknown style "" in [color]`,
+	} {
+		got := ""
+		if err := checkMarkup(text); err != nil {
+			got = err.Error()
+		}
+		if got != want {
+			t.Errorf("checkMarkup(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
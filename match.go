@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Matcher decides whether an answer given to a Checker is right.
+type Matcher interface {
+	Match(answer string, correct []string) Verdict
+}
+
+// Verdict is how an answer compares to the closest right answer.
+// Credit is how much of it was right, from 0 to 1. Words are the
+// answer's words marked right or wrong, for matchers that go word by
+// word.
+type Verdict struct {
+	Right  bool
+	Credit float64
+	Words  []WordMark
+}
+
+type WordMark struct {
+	Word string
+	OK   bool
+}
+
+// matchers are the Matchers a scene can ask for by name.
+var matchers = map[string]Matcher{
+	"exact": ExactMatcher{},
+	"words": WordMatcher{},
+	"typos": WordMatcher{Typos: true},
+}
+
+// ExactMatcher wants the answer as it is, ignoring case and spaces.
+type ExactMatcher struct{}
+
+func (ExactMatcher) Match(answer string, correct []string) Verdict {
+	squash := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), " ", "")
+	}
+	for _, c := range correct {
+		if squash(c) == squash(answer) {
+			return Verdict{Right: true, Credit: 1}
+		}
+	}
+	return Verdict{}
+}
+
+// WordMatcher compares the answer word by word, ignoring case and
+// punctuation. Words given in the right order earn credit even if
+// others are missing or wrong. With Typos, a word can be a letter or
+// two out, depending on its length, and still count.
+type WordMatcher struct {
+	Typos bool
+
+	// LooseMarks ignores accents, nasal hooks, the stroke through ł and
+	// glottal stops, for learners whose keyboards don't have them.
+	LooseMarks bool
+
+	// Code, if set, means the right answers are in English and the
+	// answer should be them encoded into Navajo code. Any of a letter's
+	// Type 1 words will do.
+	Code *Translator
+}
+
+// slot is one place in a right answer: the spellings that fill it,
+// each split into words. Most have one spelling of one word, but a
+// letter can be spelt with any of its Type 1 words.
+type slot [][]string
+
+func (wm WordMatcher) Match(answer string, correct []string) Verdict {
+	words := splitWords(normGlottal(answer))
+	keys := make([]string, len(words))
+	for i, w := range words {
+		keys[i] = wm.key(w)
+	}
+	best := Verdict{Words: markAll(words, false)}
+
+	for _, c := range correct {
+		slots := wm.slots(c)
+		if len(slots) == 0 {
+			continue
+		}
+		// "aircraftcarrier" is as right as it ever was
+		if wm.Code == nil && strings.Join(keys, "") == joinSlots(slots) {
+			return Verdict{true, 1, markAll(words, true)}
+		}
+
+		marks, matched := wm.align(keys, slots)
+		for i := range marks {
+			marks[i].Word = words[i]
+		}
+		// words taken up by a slot of more than one word count once
+		n := len(words)
+		for _, m := range marks {
+			if m.OK {
+				n--
+			}
+		}
+		n += matched
+		if len(slots) > n {
+			n = len(slots)
+		}
+
+		v := Verdict{matched == n, float64(matched) / float64(n), marks}
+		if v.Right {
+			return v
+		}
+		if v.Credit > best.Credit {
+			best = v
+		}
+	}
+	return best
+}
+
+// slots splits a right answer into slots, encoding it first if the
+// answer should be in code.
+func (wm WordMatcher) slots(correct string) []slot {
+	var slots []slot
+	if wm.Code == nil {
+		for _, w := range splitWords(normGlottal(correct)) {
+			slots = append(slots, slot{{wm.key(w)}})
+		}
+		return slots
+	}
+
+	for _, word := range wm.Code.Encode(correct) {
+		for _, cw := range word {
+			var s slot
+			for _, sp := range wm.Code.Spellings(cw) {
+				var ws []string
+				for _, w := range splitWords(normGlottal(sp)) {
+					ws = append(ws, wm.key(w))
+				}
+				if len(ws) > 0 {
+					s = append(s, ws)
+				}
+			}
+			slots = append(slots, s)
+		}
+	}
+	return slots
+}
+
+// joinSlots is the first spelling of each slot, run together.
+func joinSlots(slots []slot) string {
+	var b strings.Builder
+	for _, s := range slots {
+		if len(s) > 0 {
+			b.WriteString(strings.Join(s[0], ""))
+		}
+	}
+	return b.String()
+}
+
+// key is a word as it's compared.
+func (wm WordMatcher) key(w string) string {
+	w = decompose(w)
+	if wm.LooseMarks {
+		w = foldMarks(w)
+	}
+	return w
+}
+
+// align finds the most slots of the right answer that are filled by
+// words of the answer in the same order (their longest common
+// subsequence), and marks the words that fill them.
+func (wm WordMatcher) align(keys []string, slots []slot) ([]WordMark, int) {
+	// lcs[i][j] is the alignment of keys[i:] and slots[j:]
+	lcs := make([][]int, len(keys)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(slots)+1)
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		for j := len(slots) - 1; j >= 0; j-- {
+			lcs[i][j] = lcs[i+1][j]
+			if lcs[i][j+1] > lcs[i][j] {
+				lcs[i][j] = lcs[i][j+1]
+			}
+			if k := wm.fills(keys[i:], slots[j]); k > 0 && lcs[i+k][j+1]+1 > lcs[i][j] {
+				lcs[i][j] = lcs[i+k][j+1] + 1
+			}
+		}
+	}
+
+	marks := make([]WordMark, len(keys))
+	for i, j := 0, 0; i < len(keys) && j < len(slots); {
+		k := wm.fills(keys[i:], slots[j])
+		switch {
+		case k > 0 && lcs[i][j] == lcs[i+k][j+1]+1:
+			for ; k > 0; k-- {
+				marks[i].OK = true
+				i++
+			}
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return marks, lcs[0][0]
+}
+
+// fills is how many of keys, from the first, fill s, or 0 if they
+// don't. A spelling of several words can also be given as one.
+func (wm WordMatcher) fills(keys []string, s slot) int {
+	best := 0
+	for _, sp := range s {
+		if len(sp) > 1 && wm.same(keys[0], strings.Join(sp, "")) && best < 1 {
+			best = 1
+		}
+		if len(sp) > len(keys) || len(sp) <= best {
+			continue
+		}
+		ok := true
+		for x, w := range sp {
+			if !wm.same(keys[x], w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			best = len(sp)
+		}
+	}
+	return best
+}
+
+// same reports whether an answer word counts as the right word w.
+func (wm WordMatcher) same(answer, w string) bool {
+	if answer == w {
+		return true
+	}
+	if !wm.Typos {
+		return false
+	}
+	return levenshtein(answer, w) <= typosAllowed(w)
+}
+
+// typosAllowed is how many letters a word can be out: none for short
+// words, where a typo is likely another word, and more for long ones.
+func typosAllowed(w string) int {
+	switch n := len([]rune(w)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// levenshtein is the number of runes to insert, delete or change to
+// turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// normGlottal makes the apostrophes people type for a glottal stop the
+// modifier letter Navajo is written with.
+func normGlottal(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\'', '’', '‘', 'ʻ':
+			return 'ʼ'
+		}
+		return r
+	}, s)
+}
+
+// navajoMarks are the Navajo letters with marks, and what they are
+// made of: a base letter, a nasal hook (U+0328) if nasal and a high
+// tone mark (U+0301) if high, in that order.
+var navajoMarks = map[rune]string{
+	'á': "a\u0301", 'é': "e\u0301", 'í': "i\u0301", 'ó': "o\u0301",
+	'ą': "a\u0328", 'ę': "e\u0328", 'į': "i\u0328", 'ǫ': "o\u0328",
+	'Á': "A\u0301", 'É': "E\u0301", 'Í': "I\u0301", 'Ó': "O\u0301",
+	'Ą': "A\u0328", 'Ę': "E\u0328", 'Į': "I\u0328", 'Ǫ': "O\u0328",
+}
+
+// decompose splits the Navajo letters in s into their marks, so that
+// "į́" is the same however it was typed.
+func decompose(s string) string {
+	var out []rune
+	for _, r := range s {
+		if d, ok := navajoMarks[r]; ok {
+			out = append(out, []rune(d)...)
+			continue
+		}
+		// a high tone typed before the nasal hook
+		if n := len(out); r == '\u0328' && n > 0 && out[n-1] == '\u0301' {
+			out[n-1], r = r, out[n-1]
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// foldMarks drops the marks from a decomposed word, as well as glottal
+// stops, and makes ł an l.
+func foldMarks(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\u0301', '\u0328', 'ʼ':
+			return -1
+		case 'ł':
+			return 'l'
+		case 'Ł':
+			return 'L'
+		}
+		return r
+	}, s)
+}
+
+func markAll(words []string, ok bool) []WordMark {
+	marks := make([]WordMark, len(words))
+	for i, w := range words {
+		marks[i] = WordMark{w, ok}
+	}
+	return marks
+}
+
+// drawVerdict draws the answer's words in r, the wrong ones as
+// mistakes, followed by how many were right.
+func drawVerdict(v Verdict, r *Rect, w Window) {
+	FillRect(' ', r, w)
+	if len(v.Words) == 0 {
+		return
+	}
+
+	x, y := r.x, r.y
+	for _, wm := range v.Words {
+		n := textWidth(wm.Word)
+		if x > r.x && x+n > r.x+r.w {
+			x, y = r.x, y+1
+		}
+		if y >= r.y+r.h {
+			return
+		}
+		s := mistake
+		if wm.OK {
+			s = normal
+		}
+		DrawText(wm.Word, &Rect{x, y, r.w - (x - r.x), 1}, s, w)
+		x += n + 1
+	}
+
+	note := fmt.Sprintf("(%d%% right)", int(v.Credit*100+0.5))
+	if x+len(note) > r.x+r.w {
+		x, y = r.x, y+1
+	}
+	if y < r.y+r.h {
+		DrawText(note, &Rect{x, y, len(note), 1}, normal, w)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExactMatcher(t *testing.T) {
+	for _, tt := range []struct {
+		answer string
+		right  bool
+	}{
+		{"aircraft carrier", true},
+		{"Aircraft  Carrier", true},
+		{"aircraftcarrier", true},
+		{"aircraft carrier!", false},
+		{"carrier", false},
+	} {
+		v := ExactMatcher{}.Match(tt.answer, []string{"submarine", "aircraft carrier"})
+		if v.Right != tt.right || v.Credit != map[bool]float64{true: 1}[tt.right] {
+			t.Errorf("Match(%q) = %+v, want right %v", tt.answer, v, tt.right)
+		}
+	}
+}
+
+func TestWordMatcher(t *testing.T) {
+	correct := []string{"the quick brown fox", "a fox"}
+	for _, tt := range []struct {
+		typos  bool
+		answer string
+		right  bool
+		credit float64
+		ok     []bool // each word of the answer marked right
+	}{
+		{false, "the quick brown fox", true, 1, []bool{true, true, true, true}},
+		{false, "The Quick, Brown fox!", true, 1, []bool{true, true, true, true}},
+		{false, "thequickbrownfox", true, 1, []bool{true}},
+		{false, "A fox", true, 1, []bool{true, true}},
+		{false, "the quick fox", false, 0.75, []bool{true, true, true}},
+		{false, "the very quick brown fox", false, 0.8, []bool{true, false, true, true, true}},
+		{false, "fox brown quick the", false, 0.25, []bool{false, false, false, true}},
+		{false, "the quack brwn fox", false, 0.5, []bool{true, false, false, true}},
+		{false, "", false, 0, []bool{}},
+		{true, "the quack brwn fox", true, 1, []bool{true, true, true, true}},
+		// too short to be let off a typo
+		{true, "teh quick brown fox", false, 0.75, []bool{false, true, true, true}},
+		// too many
+		{true, "the qiukc brown fox", false, 0.75, []bool{true, false, true, true}},
+	} {
+		v := WordMatcher{Typos: tt.typos}.Match(tt.answer, correct)
+		ok := []bool{}
+		for _, m := range v.Words {
+			ok = append(ok, m.OK)
+		}
+		if v.Right != tt.right || v.Credit != tt.credit || !reflect.DeepEqual(ok, tt.ok) {
+			t.Errorf("Match(%q), typos %v = %v, %v, %v, want %v, %v, %v", tt.answer, tt.typos, v.Right, v.Credit, ok, tt.right, tt.credit, tt.ok)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	slots := func(words ...string) []slot {
+		var s []slot
+		for _, w := range words {
+			s = append(s, slot{{w}})
+		}
+		return s
+	}
+	for _, tt := range []struct {
+		keys    []string
+		slots   []slot
+		ok      []bool
+		matched int
+	}{
+		{[]string{"a", "b", "c"}, slots("a", "b", "c"), []bool{true, true, true}, 3},
+		{[]string{"a", "x", "b", "c"}, slots("a", "b", "c"), []bool{true, false, true, true}, 3},
+		{[]string{"a", "c"}, slots("a", "b", "c"), []bool{true, true}, 2},
+		{[]string{"c", "b", "a"}, slots("a", "b", "c"), []bool{false, false, true}, 1},
+		{[]string{"a", "a"}, slots("a"), []bool{true, false}, 1},
+		{[]string{"b", "a", "b"}, slots("a", "b"), []bool{false, true, true}, 2},
+		{nil, slots("a"), []bool{}, 0},
+		{[]string{"a"}, nil, []bool{false}, 0},
+	} {
+		marks, matched := WordMatcher{}.align(tt.keys, tt.slots)
+		ok := []bool{}
+		for _, m := range marks {
+			ok = append(ok, m.OK)
+		}
+		if matched != tt.matched || !reflect.DeepEqual(ok, tt.ok) {
+			t.Errorf("align(%q) = %v, %d, want %v, %d", tt.keys, ok, matched, tt.ok, tt.matched)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "ab", 2},
+		{"shash", "shash", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"quick", "qiukc", 3},
+		{"náá", "naa", 2},
+		{"tłʼízí", "tłʼizi", 2},
+	} {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestTyposAllowed(t *testing.T) {
+	for w, want := range map[string]int{
+		"cat":       0,
+		"bear":      1,
+		"giraffe":   1,
+		"elephant":  2,
+		"wóláchííʼ": 2,
+		"mósí":      1,
+	} {
+		if got := typosAllowed(w); got != want {
+			t.Errorf("typosAllowed(%q) = %d, want %d", w, got, want)
+		}
+	}
+}
+
+func TestWordMatcherCode(t *testing.T) {
+	tr := testTranslator(t)
+	for _, tt := range []struct {
+		loose   bool
+		english string
+		answer  string
+		right   bool
+		credit  float64
+	}{
+		{false, "ab", "wóláchííʼ shash", true, 1},
+		// any of a letter's Type 1 words, in its words or as one
+		{false, "ab", "be-la-sana shash", true, 1},
+		{false, "ab", "be la sana shash", true, 1},
+		{false, "ab", "belasana shash", true, 1},
+		{false, "ab", "shash shash", false, 0.5},
+		{false, "ab", "shash", false, 0.5},
+		{false, "submarine", "béésh łóóʼ", true, 1},
+		{false, "submarine", "beesh loo", false, 0},
+		{true, "submarine", "beesh loo", true, 1},
+		{false, "ab", "wolachii shash", false, 0.5},
+		{true, "ab", "wolachii shash", true, 1},
+		// typed apostrophes are glottal stops
+		{false, "g", "tł'ízí", true, 1},
+		{false, "g", "tł’ízí", true, 1},
+	} {
+		v := WordMatcher{LooseMarks: tt.loose, Code: tr}.Match(tt.answer, []string{tt.english})
+		if v.Right != tt.right || v.Credit != tt.credit {
+			t.Errorf("Match(%q) for %q, loose %v = %v, %v, want %v, %v", tt.answer, tt.english, tt.loose, v.Right, v.Credit, tt.right, tt.credit)
+		}
+	}
+}
+
+func TestWordMatcherMarks(t *testing.T) {
+	// the same letters however the marks were typed
+	for _, answer := range []string{
+		"t\u0142\u02bc\u00edz\u00ed",
+		"t\u0142\u02bci\u0301zi\u0301",
+		"t\u0142'\u00edz\u00ed",
+	} {
+		if v := (WordMatcher{}).Match(answer, []string{"t\u0142\u02bc\u00edz\u00ed"}); !v.Right {
+			t.Errorf("Match(%q) = %+v, want right", answer, v)
+		}
+	}
+	if v := (WordMatcher{}).Match("tlizi", []string{"t\u0142\u02bc\u00edz\u00ed"}); v.Right {
+		t.Errorf("Match without marks = %+v, want wrong", v)
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"shash", "shash"},
+		{"\u00e1", "a\u0301"},
+		{"\u0104", "A\u0328"},
+		// nasal and high, whichever order the marks came in
+		{"\u012f\u0301", "i\u0328\u0301"},
+		{"\u00ed\u0328", "i\u0328\u0301"},
+		{"i\u0301\u0328", "i\u0328\u0301"},
+		{"i\u0328\u0301", "i\u0328\u0301"},
+		{"\u0328", "\u0328"},
+		{"\u0142\u00e9", "\u0142e\u0301"},
+	} {
+		if got := decompose(tt.in); got != tt.want {
+			t.Errorf("decompose(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFoldMarks(t *testing.T) {
+	for in, want := range map[string]string{
+		"shash":        "shash",
+		"tłʼízí":       "tlizi",
+		"Łééʼ":         "Lee",
+		"beeʼeldǫ́ǫ́h": "beeeldooh",
+	} {
+		if got := foldMarks(decompose(in)); got != want {
+			t.Errorf("foldMarks(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormGlottal(t *testing.T) {
+	for _, in := range []string{"tł'ízí", "tł’ízí", "tł‘ízí", "tłʻízí", "tłʼízí"} {
+		if got := normGlottal(in); got != "tłʼízí" {
+			t.Errorf("normGlottal(%q) = %q", in, got)
+		}
+	}
+}
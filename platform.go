@@ -0,0 +1,24 @@
+//go:build !(js && wasm)
+
+package main
+
+// newWindow, newSfx and readFile are how main shows the tutorial, plays
+// sounds and reads scenes and dictionaries. In a terminal that is tcell,
+// beep and the assets built into the binary (see assets.go); see
+// platform_wasm.go for the browser.
+func newWindow(width, height int) Window {
+	return NewTermWindow(width, height)
+}
+
+// newSfx falls back to a NullSfx for a sound that won't load, so the
+// tutorial still runs without a speaker.
+func newSfx(filename string) SoundEffect {
+	sfx, err := NewBeepSfx(filename)
+	if err != nil {
+		if sfxErr == nil {
+			sfxErr = err
+		}
+		return NullSfx{}
+	}
+	return sfx
+}
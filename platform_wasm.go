@@ -0,0 +1,60 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall/js"
+)
+
+// In the browser the tutorial is drawn by wnct/tcell.js, and scenes and
+// dictionaries are fetched relative to the page. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o wnct/lib.wasm
+//
+// and serve the repository root, opening /wnct/tcell.html. A theme can
+// be picked with ?theme=high-contrast, for example.
+func init() {
+	*sceneFlag = "../scenes/main.json"
+	*dictFlag = "../dict/master.tsv"
+	*learnerFlag = "" // there is no file system to save progress in
+
+	search := js.Global().Get("location").Get("search").String()
+	if q, err := url.ParseQuery(strings.TrimPrefix(search, "?")); err == nil && q.Get("theme") != "" {
+		*themeFlag = q.Get("theme")
+	}
+}
+
+func newWindow(width, height int) Window {
+	return NewWebWindow(width, height)
+}
+
+func newSfx(filename string) SoundEffect {
+	return NewWebSfx(filename)
+}
+
+// readFile fetches path relative to the page.
+func readFile(path string) ([]byte, error) {
+	base, err := url.Parse(js.Global().Get("location").Get("href").String())
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(base.ResolveReference(ref).String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// Progress is how far one learner got through the scene: the lesson
+// (the index into the scene's DiscretePlayer) they were last on, and the
+// ids of the checkers they have answered.
+type Progress struct {
+	Lesson    int      `json:"lesson"`
+	Completed []string `json:"completed,omitempty"`
+}
+
+// ProgressFile is a JSON file of every learner's Progress, keyed by
+// their name.
+type ProgressFile struct {
+	Path string
+}
+
+// defaultProgressPath is where progress is kept unless told otherwise,
+// in the user's config directory if they have one.
+func defaultProgressPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "progress.json"
+	}
+	return filepath.Join(dir, "nct", "progress.json")
+}
+
+// defaultLearner is the name of the user running the tutorial, or ""
+// if it can't be found.
+func defaultLearner() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+func (pf *ProgressFile) read() (map[string]Progress, error) {
+	learners := map[string]Progress{}
+	b, err := os.ReadFile(pf.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return learners, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &learners); err != nil {
+		return nil, fmt.Errorf("%s: %v", pf.Path, err)
+	}
+	return learners, nil
+}
+
+// Load returns the learner's Progress, which is the zero Progress for
+// someone not in the file.
+func (pf *ProgressFile) Load(learner string) (Progress, error) {
+	learners, err := pf.read()
+	if err != nil {
+		return Progress{}, err
+	}
+	return learners[learner], nil
+}
+
+// Save replaces the learner's Progress, leaving everyone else's be.
+func (pf *ProgressFile) Save(learner string, p Progress) error {
+	learners, err := pf.read()
+	if err != nil {
+		return err
+	}
+	learners[learner] = p
+
+	b, err := json.MarshalIndent(learners, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(pf.Path), 0755); err != nil {
+		return err
+	}
+
+	// write then rename, so quitting mid-save can't lose everyone's
+	tmp := pf.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pf.Path)
+}
+
+// ProgressTracker saves a learner's Progress as they play a scene, and
+// takes them back to where they left off.
+type ProgressTracker struct {
+	file    *ProgressFile
+	learner string
+	p       Progress
+	player  *DiscretePlayer
+
+	// Err is the first error saving, reported once the screen is closed.
+	Err error
+}
+
+// NewProgressTracker loads the learner's Progress from file.
+func NewProgressTracker(file *ProgressFile, learner string) (*ProgressTracker, error) {
+	p, err := file.Load(learner)
+	if err != nil {
+		return nil, err
+	}
+	return &ProgressTracker{file: file, learner: learner, p: p}, nil
+}
+
+// track follows the lessons of dp; the Session's LoadScene calls lesson
+// as it advances.
+func (pt *ProgressTracker) track(dp *DiscretePlayer) {
+	pt.player = dp
+}
+
+func (pt *ProgressTracker) lesson(index int) {
+	pt.p.Lesson = index
+	pt.save()
+}
+
+func (pt *ProgressTracker) completed(id string) {
+	if contains(pt.p.Completed, id) {
+		return
+	}
+	pt.p.Completed = append(pt.p.Completed, id)
+	pt.save()
+}
+
+func (pt *ProgressTracker) save() {
+	if err := pt.file.Save(pt.learner, pt.p); err != nil && pt.Err == nil {
+		pt.Err = err
+	}
+}
+
+// CanResume reports whether the learner left off past the first lesson.
+func (pt *ProgressTracker) CanResume() bool {
+	return pt != nil && pt.player != nil && pt.p.Lesson > 0
+}
+
+// Resume makes the scene go to the lesson the learner left off at once
+// the current one is done.
+func (pt *ProgressTracker) Resume() {
+	if pt.CanResume() {
+		pt.player.Seek(pt.p.Lesson)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// QuizHints is what hovering over a code word in a quiz shows.
+type QuizHints uint8
+
+const (
+	// HintNone shows nothing; code words aren't hoverable.
+	HintNone QuizHints = iota
+	// HintGloss shows what the word means on its own, e.g. "ant", and
+	// leaves working out the letter to the learner.
+	HintGloss
+	// HintMeaning shows the letter or term the word stands for.
+	HintMeaning
+)
+
+// QuizLevel is how hard a Quiz's questions are. Letters is how many
+// letters a question spells out in Type 1 code.
+type QuizLevel struct {
+	MinLetters, MaxLetters int // 0 for no limit
+	Type2                  bool
+	Hints                  QuizHints
+}
+
+// quizLevels are the levels a scene can ask for by name.
+var quizLevels = map[string]QuizLevel{
+	"easy":   {3, 5, false, HintMeaning},
+	"medium": {0, 8, true, HintGloss},
+	"hard":   {6, 0, true, HintNone},
+}
+
+// allows reports whether encoded, a question, is at the level.
+func (ql QuizLevel) allows(encoded [][]CodeWord) bool {
+	letters, type2 := 0, false
+	for _, word := range encoded {
+		for _, cw := range word {
+			switch cw.Type {
+			case Type1:
+				letters++
+			case Type2:
+				type2 = true
+			case Untranslated:
+				return false
+			}
+		}
+	}
+
+	if type2 && !ql.Type2 {
+		return false
+	}
+	if ql.MaxLetters > 0 && letters > ql.MaxLetters {
+		return false
+	}
+	return letters >= ql.MinLetters && (letters > 0 || type2)
+}
+
+// quizQuestions are the questions from words at level, encoded by tr.
+func quizQuestions(words []string, level QuizLevel, tr *Translator) []string {
+	var qs []string
+	for _, w := range words {
+		if level.allows(tr.Encode(w)) {
+			qs = append(qs, w)
+		}
+	}
+	return qs
+}
+
+// LoadQuizWords reads a quiz word list: one English word or phrase a
+// line, with blank lines and lines starting with # ignored.
+func LoadQuizWords(path string) ([]string, error) {
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return words, nil
+}
+
+// quizHintText is the question encoded by tr as a line of code words,
+// with the ones that get a hint in {braces} and their hints in rm.
+func quizHintText(encoded [][]CodeWord, hints QuizHints, rm ReplaceMap) string {
+	var parts []string
+	for _, word := range encoded {
+		var nv []string
+		for _, cw := range word {
+			hint := ""
+			switch hints {
+			case HintGloss:
+				hint = cw.Gloss
+			case HintMeaning:
+				hint = cw.English
+				if cw.Type == Type1 && cw.Gloss != "" {
+					hint += " (" + cw.Gloss + ")"
+				}
+			}
+			if hint == "" {
+				nv = append(nv, cw.Navajo)
+				continue
+			}
+
+			s := t1ne
+			if cw.Type == Type2 {
+				s = t2ne
+			}
+			rm[strings.ToLower(cw.Navajo)] = translation{hint, s}
+			nv = append(nv, "{"+cw.Navajo+"}")
+		}
+		parts = append(parts, strings.Join(nv, " "))
+	}
+	return strings.Join(parts, " / ")
+}
+
+// quizEncodeText is the question as English words for the learner to
+// encode, with the ones that get a hint in {braces} and their hints in
+// rm. The code words are the answer, so even HintMeaning only shows
+// what they mean, e.g. "bear ant nut ant nut ant" for banana.
+func quizEncodeText(encoded [][]CodeWord, hints QuizHints, rm ReplaceMap) string {
+	var parts []string
+	for _, word := range encoded {
+		// either one Type 2 term, or a word spelt out letter by letter
+		var en string
+		var hint []string
+		s := t1ne
+		for _, cw := range word {
+			en += cw.English
+			if cw.Type == Type2 {
+				s = t2ne
+			}
+
+			if hints != HintNone && cw.Gloss != "" {
+				hint = append(hint, cw.Gloss)
+			}
+		}
+
+		if len(hint) == 0 {
+			parts = append(parts, en)
+			continue
+		}
+		rm[strings.ToLower(en)] = translation{strings.Join(hint, " "), s}
+		parts = append(parts, "{"+en+"}")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestQuizLevelAllows(t *testing.T) {
+	tr := testTranslator(t)
+	for _, tt := range []struct {
+		question           string
+		easy, medium, hard bool
+	}{
+		{"cab", true, true, false},
+		{"ab", false, true, false},
+		// letters are counted across words
+		{"cab zag", false, true, true},
+		{"ab za", true, true, false},
+		{"cabzagtab", false, false, true},
+		{"submarine", false, true, false},
+		{"company cabzag", false, true, true},
+		// every letter must have a code word
+		{"the", false, false, false},
+		{"", false, false, false},
+	} {
+		for level, want := range map[string]bool{"easy": tt.easy, "medium": tt.medium, "hard": tt.hard} {
+			if got := quizLevels[level].allows(tr.Encode(tt.question)); got != want {
+				t.Errorf("%s allows %q = %v, want %v", level, tt.question, got, want)
+			}
+		}
+	}
+}
+
+func TestQuizQuestions(t *testing.T) {
+	tr := testTranslator(t)
+	words := []string{"cab", "submarine", "the", "cab zag", "company b", "ab"}
+	for level, want := range map[string][]string{
+		"easy":   {"cab"},
+		"medium": {"cab", "submarine", "cab zag", "company b", "ab"},
+		"hard":   {"cab zag"},
+	} {
+		if got := quizQuestions(words, quizLevels[level], tr); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s questions = %q, want %q", level, got, want)
+		}
+	}
+}
+
+// quizEncoded is "cab submarine fire" encoded; fire has no gloss.
+var quizEncoded = [][]CodeWord{
+	{{"mósí", "c", "cat", Type1}, {"wóláchííʼ", "a", "ant", Type1}, {"shash", "b", "bear", Type1}},
+	{{"béésh łóóʼ", "submarine", "iron fish", Type2}},
+	{{"beeʼeldǫǫh", "fire", "", Type2}},
+}
+
+func TestQuizHintText(t *testing.T) {
+	for _, tt := range []struct {
+		hints QuizHints
+		text  string
+		rm    ReplaceMap
+	}{
+		{HintNone, "mósí wóláchííʼ shash / béésh łóóʼ / beeʼeldǫǫh", ReplaceMap{}},
+		{HintGloss, "{mósí} {wóláchííʼ} {shash} / {béésh łóóʼ} / beeʼeldǫǫh", ReplaceMap{
+			"mósí":       {"cat", t1ne},
+			"wóláchííʼ":  {"ant", t1ne},
+			"shash":      {"bear", t1ne},
+			"béésh łóóʼ": {"iron fish", t2ne},
+		}},
+		{HintMeaning, "{mósí} {wóláchííʼ} {shash} / {béésh łóóʼ} / {beeʼeldǫǫh}", ReplaceMap{
+			"mósí":       {"c (cat)", t1ne},
+			"wóláchííʼ":  {"a (ant)", t1ne},
+			"shash":      {"b (bear)", t1ne},
+			"béésh łóóʼ": {"submarine", t2ne},
+			"beeʼeldǫǫh": {"fire", t2ne},
+		}},
+	} {
+		rm := ReplaceMap{}
+		if text := quizHintText(quizEncoded, tt.hints, rm); text != tt.text || !reflect.DeepEqual(rm, tt.rm) {
+			t.Errorf("hints %d: %q with %v, want %q with %v", tt.hints, text, rm, tt.text, tt.rm)
+		}
+	}
+}
+
+func TestQuizEncodeText(t *testing.T) {
+	// the code words are the answer, so no level may show them
+	glossed := ReplaceMap{
+		"cab":       {"cat ant bear", t1ne},
+		"submarine": {"iron fish", t2ne},
+	}
+	for _, tt := range []struct {
+		hints QuizHints
+		text  string
+		rm    ReplaceMap
+	}{
+		{HintNone, "cab submarine fire", ReplaceMap{}},
+		{HintGloss, "{cab} {submarine} fire", glossed},
+		{HintMeaning, "{cab} {submarine} fire", glossed},
+	} {
+		rm := ReplaceMap{}
+		if text := quizEncodeText(quizEncoded, tt.hints, rm); text != tt.text || !reflect.DeepEqual(rm, tt.rm) {
+			t.Errorf("hints %d: %q with %v, want %q with %v", tt.hints, text, rm, tt.text, tt.rm)
+		}
+	}
+}
+
+func TestLoadQuizWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.txt")
+	list := "# words to quiz on\n\ncab\n  aircraft carrier \r\n#submarine\n\n"
+	if err := os.WriteFile(path, []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	words, err := LoadQuizWords(path)
+	if want := []string{"cab", "aircraft carrier"}; err != nil || !reflect.DeepEqual(words, want) {
+		t.Errorf("LoadQuizWords = %q, %v, want %q", words, err, want)
+	}
+
+	if _, err := LoadQuizWords(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadQuizWords of a missing file succeeded")
+	}
+}
+
+func TestQuizReset(t *testing.T) {
+	for _, questions := range [][]string{{"cab", "submarine", "ab"}, {"cab"}} {
+		w := NewHeadlessWindow(40, 6)
+		q := NewQuiz(testTranslator(t), questions, HintNone, false, &Rect{0, 1, 40, 4}, w,
+			NewWaitForNext(), NewWaitForNext(), rand.New(rand.NewSource(1)))
+		for i := 0; i < 10; i++ {
+			asked := q.asked
+			q.Reset()
+			if len(questions) > 1 && q.asked == asked {
+				t.Errorf("%q: asked %q twice in a row", questions, questions[asked])
+			}
+			if want := []string{questions[q.asked]}; !reflect.DeepEqual(q.chkr.correct, want) {
+				t.Errorf("%q: asked %q, but %q is right", questions, questions[q.asked], q.chkr.correct)
+			}
+		}
+	}
+}
@@ -8,74 +8,34 @@ func (r *Rect) Contains(x, y int) bool {
 	return r.x <= x && x < r.x+r.w && r.y <= y && y < r.y+r.h
 }
 
-func GetDrawingRect(text string, r *Rect, offset int) (tr []*Rect) {
-	textRune := []rune(text)
-	x := offset % r.w
-	startX := x
-	ci := 0
-	tr = append(tr, &Rect{startX + r.x, (offset / r.w) + r.y, 0, 1})
-
-	i := 0
-	for ; i < len(textRune); i++ {
-
-		x = (i + offset) % r.w
-		if textRune[i] == '\n' {
-			if x != 0 {
-				offset += r.w - x
-			}
-			offset -= 1
-			i++
-			continue
-		} else if textRune[i] == '\t' {
-			offset += 4
-			i++
-			continue
-		}
-
-		y := (i + offset) / r.w
-
-		// new line, new rect!
-		if i != 0 && x == 0 {
-			tr[ci].w = r.w - startX
-			ci++
-			startX = 0
-			tr = append(tr, &Rect{r.x, y + r.y, 0, 1})
-		}
-
-		if y >= r.h {
-			return
-		}
-	}
-
-	tr[ci].w = x + 1 - startX
-
-	return tr
+// GetDrawingRect is the rects text covers drawn in r from offset, as
+// DrawTextOffset draws it, one for each row.
+func GetDrawingRect(text string, r *Rect, offset int) []*Rect {
+	return layoutText(text, r.w, offset).rects(r)
 }
 
+// GetDimensions is how many cells wide and high text is, laid out on
+// as many lines as it has.
 func GetDimensions(text string) (w, h int) {
-	textRune := []rune(text)
-	longestLine := 0
-	w, h = 0, 1
-	for i := 0; i < len(textRune); i++ {
-		if textRune[i] == '\n' {
+	h = 1
+	line := 0
+	for _, g := range glyphs(text) {
+		switch g.mainc {
+		case '\n':
 			h++
-			if w < longestLine {
-				w = longestLine
+			if w < line {
+				w = line
 			}
-			longestLine = 0
-			continue
+			line = 0
+		case '\t':
+			line += tabWidth
+		default:
+			line += g.width
 		}
-
-		if textRune[i] == '\t' {
-			longestLine += 4
-			continue
-		}
-
-		longestLine++
 	}
 
-	if w < longestLine {
-		w = longestLine
+	if w < line {
+		w = line
 	}
 
 	return w, h
@@ -90,7 +50,8 @@ type loc struct {
 
 type pixel struct {
 	mainc rune
-	s     style
+	combc []rune
+	s     Style
 }
 
 func CopyContent(r *Rect, w Window) VirtualRegion {
@@ -99,8 +60,8 @@ func CopyContent(r *Rect, w Window) VirtualRegion {
 	for y := range vr {
 		vr[y] = make([]*pixel, r.w)
 		for x := range vr[y] {
-			mainc, style := w.GetContent(x+r.x, y+r.y)
-			vr[y][x] = &pixel{mainc, style}
+			mainc, combc, s := w.GetContent(x+r.x, y+r.y)
+			vr[y][x] = &pixel{mainc, combc, s}
 		}
 	}
 
@@ -116,7 +77,7 @@ func (vr VirtualRegion) PasteContent(x, y int, w Window) {
 	for ny := 0; ny < len(vr); ny++ {
 		for nx := 0; nx < len(vr[ny]); nx++ {
 			px := vr.GetContent(nx, ny)
-			w.SetContent(x+nx, y+ny, px.mainc, px.s)
+			w.SetContent(x+nx, y+ny, px.mainc, px.combc, px.s)
 		}
 	}
 }
@@ -146,5 +107,13 @@ func (vr VirtualRegion) Equals(other VirtualRegion) bool {
 }
 
 func (px *pixel) Equals(other *pixel) bool {
-	return px.mainc == other.mainc
+	if px.mainc != other.mainc || px.s != other.s || len(px.combc) != len(other.combc) {
+		return false
+	}
+	for i, c := range px.combc {
+		if other.combc[i] != c {
+			return false
+		}
+	}
+	return true
 }
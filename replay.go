@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Replay plays a script of inputs through the run loop against a scene
+// on a HeadlessWindow, checking the screen at checkpoints. Each line of
+// a script is one of
+//
+//	type TEXT      type TEXT, one key per frame
+//	key KEY        press KEY for a frame; space, enter, up, down, left,
+//	               right, backspace, delete, home, end, ctrl-w, reset,
+//	               quit or a single character
+//	mouse X Y      move the mouse to X, Y for a frame
+//	click X Y      click at X, Y for a frame, and let go the next
+//	resize W H     resize the window to W by H for a frame
+//	tick [N]       let N frames pass with no input, 1 if N is not given
+//	expect TEXT    fail unless TEXT is on screen
+//	reject TEXT    fail if TEXT is on screen
+//	snapshot NAME  fail unless the screen matches the snapshot NAME
+//
+// Blank lines and lines starting with # are ignored.
+type Replay struct {
+	w *HeadlessWindow
+	l *loop
+
+	// Snapshots is the directory snapshot files are kept in. If Update
+	// is set, snapshots are written instead of compared.
+	Snapshots string
+	Update    bool
+
+	name     string
+	lineNo   int
+	stopped  bool
+	Failures []string
+}
+
+func NewReplay(scene Element, w *HeadlessWindow, snapshots string) *Replay {
+	return &Replay{w, &loop{w: w, scene: scene}, snapshots, false, "", 0, false, nil}
+}
+
+// Run plays the script read from r; name is used in failures. It
+// returns an error if the script can't be read or has a bad line;
+// failed checks are collected in Failures.
+func (rp *Replay) Run(name string, r io.Reader) error {
+	rp.name = name
+	rp.lineNo = 0
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() && !rp.stopped {
+		rp.lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := rp.step(line); err != nil {
+			return fmt.Errorf("%s:%d: %v", name, rp.lineNo, err)
+		}
+	}
+	return sc.Err()
+}
+
+func (rp *Replay) step(line string) error {
+	cmd, arg := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		cmd, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch cmd {
+	case "type":
+		for _, r := range arg {
+			rp.input(&keyEvent{r})
+		}
+
+	case "key":
+		e, err := parseKey(arg)
+		if err != nil {
+			return err
+		}
+		rp.input(e)
+
+	case "mouse", "click":
+		var x, y int
+		if _, err := fmt.Sscan(arg, &x, &y); err != nil {
+			return fmt.Errorf("%s needs x and y: %v", cmd, err)
+		}
+		rp.input(&mouseEvent{x, y, cmd == "click"})
+		if cmd == "click" {
+			rp.input(&mouseEvent{x, y, false})
+		}
+
+	case "resize":
+		var width, height int
+		if _, err := fmt.Sscan(arg, &width, &height); err != nil {
+			return fmt.Errorf("resize needs a width and height: %v", err)
+		}
+		rp.input(&resizeEvent{width, height})
+		if rp.l.err != nil {
+			return rp.l.err
+		}
+
+	case "tick":
+		n := 1
+		if arg != "" {
+			var err error
+			if n, err = strconv.Atoi(arg); err != nil || n < 0 {
+				return fmt.Errorf("bad tick count %q", arg)
+			}
+		}
+		for i := 0; i < n; i++ {
+			rp.l.frame()
+		}
+
+	case "expect", "reject":
+		if arg == "" {
+			return fmt.Errorf("%s needs some text", cmd)
+		}
+		if on := strings.Contains(rp.w.Dump(), arg); on != (cmd == "expect") {
+			if on {
+				rp.fail("%q is on screen", arg)
+			} else {
+				rp.fail("%q is not on screen", arg)
+			}
+		}
+
+	case "snapshot":
+		if arg == "" || strings.ContainsAny(arg, `/\`) {
+			return fmt.Errorf("bad snapshot name %q", arg)
+		}
+		return rp.snapshot(arg)
+
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	return nil
+}
+
+// input sends e through the loop and plays a frame with it.
+func (rp *Replay) input(e event) {
+	if rp.stopped {
+		return
+	}
+	if !rp.l.handle(e) {
+		rp.w.Fini()
+		rp.stopped = true
+		return
+	}
+	rp.l.frame()
+}
+
+func (rp *Replay) snapshot(name string) error {
+	path := filepath.Join(rp.Snapshots, name+".txt")
+	got := rp.w.Dump()
+
+	if rp.Update {
+		if err := os.MkdirAll(rp.Snapshots, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(got), 0644)
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if got == string(want) {
+		return nil
+	}
+
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, wl string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			wl = wantLines[i]
+		}
+		if g != wl {
+			rp.fail("snapshot %s differs at row %d:\n\twant %q\n\tgot  %q", name, i, wl, g)
+			break
+		}
+	}
+	return nil
+}
+
+func (rp *Replay) fail(format string, args ...any) {
+	rp.Failures = append(rp.Failures, fmt.Sprintf("%s:%d: ", rp.name, rp.lineNo)+fmt.Sprintf(format, args...))
+}
+
+// parseKey turns a key name from a script into an event.
+func parseKey(name string) (event, error) {
+	switch name {
+	case "space":
+		return &keyEvent{' '}, nil
+	case "up":
+		return &specialEvent{up}, nil
+	case "down":
+		return &specialEvent{down}, nil
+	case "left":
+		return &specialEvent{left}, nil
+	case "right":
+		return &specialEvent{right}, nil
+	case "backspace":
+		return &specialEvent{backspace}, nil
+	case "delete":
+		return &specialEvent{del}, nil
+	case "home":
+		return &specialEvent{home}, nil
+	case "end":
+		return &specialEvent{end}, nil
+	case "ctrl-w":
+		return &specialEvent{deleteWord}, nil
+	case "enter":
+		return &specialEvent{enter}, nil
+	case "quit":
+		return &specialEvent{quit}, nil
+	case "reset":
+		return &specialEvent{reset}, nil
+	}
+
+	if r := []rune(name); len(r) == 1 {
+		return &keyEvent{r[0]}, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", name)
+}
+
+// replayCmd plays each script given against a fresh copy of the scene,
+// and fails if any check in them does.
+func replayCmd(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	scenePath := fs.String("scene", *sceneFlag, "scene file to play")
+	snapshots := fs.String("snapshots", "scripts/snapshots", "directory of snapshot files")
+	update := fs.Bool("update", false, "write snapshots instead of checking them")
+	width := fs.Int("width", 79, "window width")
+	height := fs.Int("height", 20, "window height")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("replay needs at least one script")
+	}
+
+	failed := 0
+	for _, script := range fs.Args() {
+		hw := NewHeadlessWindow(*width, *height)
+		elm, err := LoadScene(*scenePath, hw, silentSfx, nil)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(script)
+		if err != nil {
+			return err
+		}
+		rp := NewReplay(elm, hw, *snapshots)
+		rp.Update = *update
+		err = rp.Run(script, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, failure := range rp.Failures {
+			fmt.Fprintln(os.Stderr, failure)
+		}
+		if len(rp.Failures) > 0 {
+			failed++
+			fmt.Printf("FAIL %s\n", script)
+		} else {
+			fmt.Printf("ok   %s\n", script)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scripts failed", failed, fs.NArg())
+	}
+	return nil
+}
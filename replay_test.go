@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestScripts plays every script in scripts against the scene the
+// tutorial ships with, as `nct replay` does. Run with -update to rewrite
+// their snapshots.
+func TestScripts(t *testing.T) {
+	d, err := LoadDictionary("dict/master.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseDictionary(d)
+
+	scripts, err := filepath.Glob("scripts/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) == 0 {
+		t.Fatal("no scripts")
+	}
+	for _, script := range scripts {
+		t.Run(filepath.Base(script), func(t *testing.T) {
+			w := NewHeadlessWindow(79, 20)
+			scene, err := LoadScene("scenes/main.json", w, silentSfx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			rp := NewReplay(scene, w, "scripts/snapshots")
+			rp.Update = *update
+			if err := rp.Run(script, f); err != nil {
+				t.Fatal(err)
+			}
+			for _, failure := range rp.Failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
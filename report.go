@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is a finished Session, as written for instructors.
+type Report struct {
+	Learner   string           `json:"learner"`
+	Started   time.Time        `json:"started"`
+	Finished  time.Time        `json:"finished"`
+	Questions []QuestionReport `json:"questions"`
+}
+
+// QuestionReport is how the learner did on one checker. Answers are
+// every answer given, the right one last if it was answered, and Credit
+// how much of the best of them was right.
+type QuestionReport struct {
+	Lesson   int      `json:"lesson"`
+	Question string   `json:"question"`
+	Answered bool     `json:"answered"`
+	Attempts int      `json:"attempts"`
+	Answers  []string `json:"answers"`
+	Seconds  float64  `json:"seconds"`
+	Credit   float64  `json:"credit"`
+}
+
+func NewReport(s *Session, finished time.Time) *Report {
+	rep := &Report{Learner: s.Learner, Started: s.Started, Finished: finished}
+	for _, cr := range s.Score.Results {
+		answers := append([]string{}, cr.Wrong...)
+		if cr.Answered {
+			answers = append(answers, cr.Answer)
+		}
+		rep.Questions = append(rep.Questions, QuestionReport{
+			Lesson:   cr.Lesson,
+			Question: cr.ID,
+			Answered: cr.Answered,
+			Attempts: cr.Attempts,
+			Answers:  answers,
+			Seconds:  cr.Time.Seconds(),
+			Credit:   cr.Credit,
+		})
+	}
+	return rep
+}
+
+// result is the question as a CheckResult, for scoring.
+func (qr QuestionReport) result() CheckResult {
+	return CheckResult{ID: qr.Question, Lesson: qr.Lesson, Answered: qr.Answered, Attempts: qr.Attempts, Credit: qr.Credit}
+}
+
+// reportColumns are the columns of a CSV report, one row per question.
+// Answers are joined with " | ".
+var reportColumns = []string{"learner", "started", "finished", "lesson", "question", "answered", "attempts", "seconds", "credit", "answers"}
+
+// WriteCSV writes the report with a header row of reportColumns.
+func (rep *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(reportColumns)
+	for _, q := range rep.Questions {
+		cw.Write([]string{
+			rep.Learner,
+			rep.Started.Format(time.RFC3339),
+			rep.Finished.Format(time.RFC3339),
+			strconv.Itoa(q.Lesson),
+			q.Question,
+			strconv.FormatBool(q.Answered),
+			strconv.Itoa(q.Attempts),
+			strconv.FormatFloat(q.Seconds, 'f', 1, 64),
+			strconv.FormatFloat(q.Credit, 'f', 2, 64),
+			strings.Join(q.Answers, " | "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFiles writes the report to dir as both JSON and CSV, named for
+// the learner and when they started. Sessions of the same learner
+// started in the same second are numbered; the JSON file is created
+// only if it isn't there already, so sessions finishing at once can't
+// take the same number.
+func (rep *Report) WriteFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(rep, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(dir, fileSafe(rep.Learner)+"-"+rep.Started.Format("20060102-150405"))
+	base := name
+	var jf *os.File
+	for n := 2; ; n++ {
+		jf, err = os.OpenFile(base+".json", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		base = fmt.Sprintf("%s-%d", name, n)
+	}
+	if _, err := jf.Write(b); err != nil {
+		jf.Close()
+		return err
+	}
+	if err := jf.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(base + ".csv")
+	if err != nil {
+		return err
+	}
+	if err := rep.WriteCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fileSafe makes a learner's name safe to put in a file name.
+func fileSafe(name string) string {
+	if name == "" {
+		return "anonymous"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// ReadReport reads a report written by WriteFiles, in JSON.
+func ReadReport(path string) (*Report, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rep Report
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &rep, nil
+}
+
+// ClassSummary is many sessions' reports merged, per question and per
+// learner.
+type ClassSummary struct {
+	Sessions  int
+	Questions []*QuestionSummary
+	Learners  []*LearnerSummary
+}
+
+type QuestionSummary struct {
+	Lesson   int
+	Question string
+	Asked    int // sessions the question was in
+	Answered int
+	Attempts int // over the sessions it was answered in
+	Time     time.Duration
+	Wrong    map[string]int // how often each wrong answer was given
+}
+
+// MeanAttempts and MeanTime are over the sessions the question was
+// answered in.
+func (qs *QuestionSummary) MeanAttempts() float64 {
+	if qs.Answered == 0 {
+		return 0
+	}
+	return float64(qs.Attempts) / float64(qs.Answered)
+}
+
+func (qs *QuestionSummary) MeanTime() time.Duration {
+	if qs.Answered == 0 {
+		return 0
+	}
+	return qs.Time / time.Duration(qs.Answered)
+}
+
+// CommonWrong is the most given wrong answer and how often, or "" if
+// there were none.
+func (qs *QuestionSummary) CommonWrong() (string, int) {
+	best, n := "", 0
+	for a, c := range qs.Wrong {
+		if c > n || c == n && a < best {
+			best, n = a, c
+		}
+	}
+	return best, n
+}
+
+type LearnerSummary struct {
+	Learner   string
+	Sessions  int
+	BestScore int
+	MaxScore  int
+}
+
+// Summarise merges reports into a ClassSummary. Questions are in lesson
+// order, and learners by name.
+func Summarise(reps []*Report) *ClassSummary {
+	cs := &ClassSummary{Sessions: len(reps)}
+	questions := map[string]*QuestionSummary{}
+	learners := map[string]*LearnerSummary{}
+
+	for _, rep := range reps {
+		var sc Scorecard
+		for _, q := range rep.Questions {
+			qs, ok := questions[q.Question]
+			if !ok {
+				qs = &QuestionSummary{Lesson: q.Lesson, Question: q.Question, Wrong: map[string]int{}}
+				questions[q.Question] = qs
+				cs.Questions = append(cs.Questions, qs)
+			}
+			qs.Asked++
+
+			wrong := q.Answers
+			if q.Answered && len(wrong) > 0 {
+				qs.Answered++
+				qs.Attempts += q.Attempts
+				qs.Time += time.Duration(q.Seconds * float64(time.Second))
+				wrong = wrong[:len(wrong)-1]
+			}
+			for _, a := range wrong {
+				qs.Wrong[a]++
+			}
+			sc.Results = append(sc.Results, q.result())
+		}
+
+		ls, ok := learners[rep.Learner]
+		if !ok {
+			ls = &LearnerSummary{Learner: rep.Learner}
+			learners[rep.Learner] = ls
+			cs.Learners = append(cs.Learners, ls)
+		}
+		ls.Sessions++
+		points, max := sc.Score()
+		if points > ls.BestScore || ls.Sessions == 1 {
+			ls.BestScore, ls.MaxScore = points, max
+		}
+	}
+
+	sort.SliceStable(cs.Questions, func(i, j int) bool { return cs.Questions[i].Lesson < cs.Questions[j].Lesson })
+	sort.Slice(cs.Learners, func(i, j int) bool { return cs.Learners[i].Learner < cs.Learners[j].Learner })
+	return cs
+}
+
+// WriteText writes the summary as two tables.
+func (cs *ClassSummary) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%d sessions, %d learners\n\n", cs.Sessions, len(cs.Learners))
+
+	fmt.Fprintln(tw, "Question\tLesson\tAnswered\tMean attempts\tMean time\tMost common wrong answer")
+	for _, qs := range cs.Questions {
+		wrong := "-"
+		if a, n := qs.CommonWrong(); n > 0 {
+			wrong = fmt.Sprintf("%q (%d)", a, n)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d/%d\t%.1f\t%s\t%s\n", qs.Question, qs.Lesson, qs.Answered, qs.Asked,
+			qs.MeanAttempts(), qs.MeanTime().Round(time.Second), wrong)
+	}
+
+	fmt.Fprintln(tw, "\nLearner\tSessions\tBest score")
+	for _, ls := range cs.Learners {
+		fmt.Fprintf(tw, "%s\t%d\t%d/%d\n", ls.Learner, ls.Sessions, ls.BestScore, ls.MaxScore)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes one row per question, for spreadsheets.
+func (cs *ClassSummary) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"lesson", "question", "asked", "answered", "mean_attempts", "mean_seconds", "common_wrong", "common_wrong_count"})
+	for _, qs := range cs.Questions {
+		a, n := qs.CommonWrong()
+		cw.Write([]string{
+			strconv.Itoa(qs.Lesson),
+			qs.Question,
+			strconv.Itoa(qs.Asked),
+			strconv.Itoa(qs.Answered),
+			strconv.FormatFloat(qs.MeanAttempts(), 'f', 2, 64),
+			strconv.FormatFloat(qs.MeanTime().Seconds(), 'f', 1, 64),
+			a,
+			strconv.Itoa(n),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// summaryCmd merges session reports into a class summary. Arguments are
+// JSON reports, or directories of them.
+func summaryCmd(args []string) error {
+	flags := flag.NewFlagSet("summary", flag.ExitOnError)
+	asCSV := flags.Bool("csv", false, "write the per question summary as CSV")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("summary needs report files or directories")
+	}
+
+	var reps []*Report
+	for _, arg := range flags.Args() {
+		paths := []string{arg}
+		if fi, err := os.Stat(arg); err == nil && fi.IsDir() {
+			paths, err = filepath.Glob(filepath.Join(arg, "*.json"))
+			if err != nil {
+				return err
+			}
+		}
+		for _, p := range paths {
+			rep, err := ReadReport(p)
+			if err != nil {
+				return err
+			}
+			reps = append(reps, rep)
+		}
+	}
+
+	cs := Summarise(reps)
+	if *asCSV {
+		return cs.WriteCSV(os.Stdout)
+	}
+	return cs.WriteText(os.Stdout)
+}
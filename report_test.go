@@ -0,0 +1,186 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	rep := &Report{"ada l", testStart, testStart.Add(time.Minute), []QuestionReport{
+		{1, "checker1", true, 2, []string{"bandana", "banana"}, 12.5, 1},
+	}}
+
+	// sessions finishing together each get their own files
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = rep.WriteFiles(dir)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	sort.Strings(files)
+	var want []string
+	for _, suffix := range []string{"", "-2", "-3", "-4", "-5"} {
+		want = append(want, "ada_l-20240301-093000"+suffix+".csv", "ada_l-20240301-093000"+suffix+".json")
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+
+	got, err := ReadReport(filepath.Join(dir, "ada_l-20240301-093000-3.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rep) {
+		t.Errorf("ReadReport = %+v, want %+v", got, rep)
+	}
+}
+
+func TestWriteFilesError(t *testing.T) {
+	// a name too long for a file is an error, not another number to try
+	rep := &Report{Learner: strings.Repeat("a", 300), Started: testStart}
+	if err := rep.WriteFiles(t.TempDir()); err == nil {
+		t.Error("WriteFiles with too long a name: no error")
+	}
+}
+
+func TestReportWriteCSV(t *testing.T) {
+	rep := &Report{"ada", testStart, testStart.Add(time.Minute), []QuestionReport{
+		{1, "checker1", true, 2, []string{"bandana", "banana"}, 12.5, 1},
+		{2, "checker2", false, 1, []string{"x"}, 0, 0.25},
+	}}
+	var sb strings.Builder
+	if err := rep.WriteCSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "learner,started,finished,lesson,question,answered,attempts,seconds,credit,answers\n" +
+		"ada,2024-03-01T09:30:00Z,2024-03-01T09:31:00Z,1,checker1,true,2,12.5,1.00,bandana | banana\n" +
+		"ada,2024-03-01T09:30:00Z,2024-03-01T09:31:00Z,2,checker2,false,1,0.0,0.25,x\n"
+	if sb.String() != want {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestFileSafe(t *testing.T) {
+	for name, want := range map[string]string{
+		"":             "anonymous",
+		"ada":          "ada",
+		"Ada L.":       "Ada_L.",
+		"../../etc":    ".._.._etc",
+		"10.0.0.5#3":   "10.0.0.5_3",
+		"Zoé":          "Zo_",
+		"a-b_c":        "a-b_c",
+		"tab\there":    "tab_here",
+		"slash/in/it":  "slash_in_it",
+		"back\\slash":  "back_slash",
+		"co:lon":       "co_lon",
+		"  spaces  ":   "__spaces__",
+		"UPPER lower9": "UPPER_lower9",
+	} {
+		if got := fileSafe(name); got != want {
+			t.Errorf("fileSafe(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	q := func(lesson int, id string, answered bool, attempts int, secs float64, answers ...string) QuestionReport {
+		return QuestionReport{lesson, id, answered, attempts, answers, secs, 0}
+	}
+	reps := []*Report{
+		{"zoe", testStart, testStart, []QuestionReport{
+			q(3, "lesson3", true, 1, 10, "b"),
+			q(2, "lesson2", true, 3, 30, "bandana", "banan", "banana"),
+		}},
+		{"ada", testStart, testStart, []QuestionReport{
+			q(2, "lesson2", true, 1, 20, "banana"),
+			q(3, "lesson3", false, 2, 0, "a", "c"),
+		}},
+		{"zoe", testStart, testStart, []QuestionReport{
+			q(2, "lesson2", true, 2, 10, "bandana", "banana"),
+			q(3, "lesson3", true, 1, 20, "b"),
+		}},
+	}
+	cs := Summarise(reps)
+
+	if cs.Sessions != 3 {
+		t.Errorf("Sessions = %d, want 3", cs.Sessions)
+	}
+
+	type question struct {
+		Lesson             int
+		Question           string
+		Asked, Answered    int
+		MeanAttempts       float64
+		MeanTime           time.Duration
+		CommonWrong        string
+		CommonWrongGiven   int
+		DifferentWrongOnes int
+	}
+	var got []question
+	for _, qs := range cs.Questions {
+		a, n := qs.CommonWrong()
+		got = append(got, question{qs.Lesson, qs.Question, qs.Asked, qs.Answered, qs.MeanAttempts(), qs.MeanTime(), a, n, len(qs.Wrong)})
+	}
+	want := []question{
+		{2, "lesson2", 3, 3, 2, 20 * time.Second, "bandana", 2, 2},
+		{3, "lesson3", 3, 2, 1, 15 * time.Second, "a", 1, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Questions = %+v, want %+v", got, want)
+	}
+
+	var learners []LearnerSummary
+	for _, ls := range cs.Learners {
+		learners = append(learners, *ls)
+	}
+	// zoe's first session scored 1+3, her second 2+3
+	wantLearners := []LearnerSummary{{"ada", 1, 3, 6}, {"zoe", 2, 5, 6}}
+	if !reflect.DeepEqual(learners, wantLearners) {
+		t.Errorf("Learners = %+v, want %+v", learners, wantLearners)
+	}
+
+	var sb strings.Builder
+	if err := cs.WriteCSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "lesson,question,asked,answered,mean_attempts,mean_seconds,common_wrong,common_wrong_count\n" +
+		"2,lesson2,3,3,2.00,20.0,bandana,2\n" +
+		"3,lesson3,3,2,1.00,15.0,a,1\n"
+	if sb.String() != wantCSV {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", sb.String(), wantCSV)
+	}
+}
+
+func TestSummariseNone(t *testing.T) {
+	cs := Summarise(nil)
+	if cs.Sessions != 0 || len(cs.Questions) != 0 || len(cs.Learners) != 0 {
+		t.Errorf("Summarise(nil) = %+v", cs)
+	}
+	var sb strings.Builder
+	if err := cs.WriteText(&sb); err != nil || !strings.HasPrefix(sb.String(), "0 sessions, 0 learners") {
+		t.Errorf("WriteText = %q, %v", sb.String(), err)
+	}
+}
@@ -0,0 +1,893 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sceneFile is the on-disk description of a scene. Sounds maps a name
+// to the wav file loaded for it, and Scene is the root element.
+type sceneFile struct {
+	Sounds map[string]string `json:"sounds"`
+	Scene  *elementSpec      `json:"scene"`
+}
+
+// elementSpec describes a single Element. Type picks the constructor;
+// which other fields are read depends on the type.
+type elementSpec struct {
+	Type string `json:"type"`
+
+	// players; Layout, if given, places the children for them
+	Children []*elementSpec `json:"children"`
+	Layout   *layoutSpec    `json:"layout"`
+
+	// text elements; Text can be marked up, see parseMarkup, and Dict
+	// or Replace give what words in its braces show
+	Text    string                     `json:"text"`
+	Rect    *rectSpec                  `json:"rect"`
+	Click   string                     `json:"click"`
+	Ding    string                     `json:"ding"`
+	Dict    string                     `json:"dict"`
+	Replace map[string]translationSpec `json:"replace"`
+
+	// checker and quiz; ID names it in saved progress, and defaults to
+	// its place among the scene's checkers, e.g. "checker2"
+	ID      string       `json:"id"`
+	Input   *elementSpec `json:"input"`
+	Correct []string     `json:"correct"`
+	Right   *elementSpec `json:"right"`
+	Wrong   *elementSpec `json:"wrong"`
+
+	// checker and quiz; Match is how answers are matched, exact, words
+	// or typos (words by default), and Feedback where a wrong answer is
+	// shown with its mistakes marked. Marks is strict, or loose to let
+	// Navajo be typed without its accents, nasal hooks, ł or glottal
+	// stops. Direction is decode, or encode to have English questions
+	// answered in code; a checker's correct answers are then English.
+	Match     string    `json:"match"`
+	Feedback  *rectSpec `json:"feedback"`
+	Marks     string    `json:"marks"`
+	Direction string    `json:"direction"`
+
+	// textinput and quiz; where to show Navajo letters that can be
+	// clicked on to type them
+	Chars *rectSpec `json:"chars"`
+
+	// textinput; the most letters that can be typed, if not 0
+	Max int `json:"max"`
+
+	// options
+	Options []string `json:"options"`
+
+	// quiz; Level is easy, medium or hard, and Words the word list
+	// questions are picked from, dict/quiz.txt if not given
+	Level string `json:"level"`
+	Words string `json:"words"`
+}
+
+// layoutSpec arranges the children of a player in Rect, or the whole
+// drawing rect inside the margin if not given. Type is stack, to put
+// them one under another, center, to stack them in the middle, or
+// flow, to put them side by side; Gap is the blank cells between.
+type layoutSpec struct {
+	Type string    `json:"type"`
+	Gap  int       `json:"gap"`
+	Rect *rectSpec `json:"rect"`
+}
+
+type translationSpec struct {
+	Text  string `json:"text"`
+	Style string `json:"style"`
+}
+
+// rectSpec is a Rect in MarginRect terms (x, y and h relative to the
+// drawing rect, width filling the margin), unless Absolute is set, in
+// which case x, y, w and h are screen coordinates.
+type rectSpec struct {
+	X        sceneExpr `json:"x"`
+	Y        sceneExpr `json:"y"`
+	W        sceneExpr `json:"w"`
+	H        sceneExpr `json:"h"`
+	Absolute bool      `json:"absolute"`
+}
+
+func (rs *rectSpec) toRect(w Window) (*Rect, error) {
+	vars := map[string]int{"width": w.GetWidth(), "height": w.GetHeight()}
+	var vals [4]int
+	for i, e := range []sceneExpr{rs.X, rs.Y, rs.W, rs.H} {
+		v, err := e.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+
+	if rs.Absolute {
+		return &Rect{vals[0], vals[1], vals[2], vals[3]}, nil
+	}
+	return MarginRect(vals[0], vals[1], vals[3], w), nil
+}
+
+// sceneExpr is an integer expression, written either as a JSON number
+// or as a string such as "(width-40-12)/2". The variables width and
+// height are the size of the window the scene is loaded into.
+type sceneExpr string
+
+func (se *sceneExpr) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*se = sceneExpr(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("expression must be a number or string, got %s", b)
+	}
+	*se = sceneExpr(n)
+	return nil
+}
+
+func (se sceneExpr) eval(vars map[string]int) (int, error) {
+	if strings.TrimSpace(string(se)) == "" {
+		return 0, nil
+	}
+
+	p := &exprParser{[]rune(string(se)), 0, vars}
+	v, err := p.parseSum()
+	if err != nil {
+		return 0, fmt.Errorf("expression %q: %v", se, err)
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return 0, fmt.Errorf("expression %q: unexpected %q", se, p.src[p.pos])
+	}
+	return v, nil
+}
+
+// exprParser is a recursive descent parser for sceneExpr. Division
+// truncates, like it does in Go.
+type exprParser struct {
+	src  []rune
+	pos  int
+	vars map[string]int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() rune {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *exprParser) parseSum() (int, error) {
+	v, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			r, err := p.parseProduct()
+			if err != nil {
+				return 0, err
+			}
+			v += r
+		case '-':
+			p.pos++
+			r, err := p.parseProduct()
+			if err != nil {
+				return 0, err
+			}
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) parseProduct() (int, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*':
+			p.pos++
+			r, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			v *= r
+		case '/':
+			p.pos++
+			r, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			v /= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (int, error) {
+	switch c := p.peek(); {
+	case c == '-':
+		p.pos++
+		v, err := p.parseUnary()
+		return -v, err
+	case c == '(':
+		p.pos++
+		v, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return v, nil
+	case unicode.IsDigit(c):
+		start := p.pos
+		for p.pos < len(p.src) && unicode.IsDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		return strconv.Atoi(string(p.src[start:p.pos]))
+	case unicode.IsLetter(c):
+		start := p.pos
+		for p.pos < len(p.src) && unicode.IsLetter(p.src[p.pos]) {
+			p.pos++
+		}
+		name := string(p.src[start:p.pos])
+		v, ok := p.vars[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %q", name)
+		}
+		return v, nil
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end")
+	default:
+		return 0, fmt.Errorf("unexpected %q", c)
+	}
+}
+
+// dictionaries are the ReplaceMaps a scene can refer to by name.
+var dictionaries = map[string]ReplaceMap{
+	"master": master,
+}
+
+// LoadScene reads the scene file at path and builds its elements
+// for w, loading its sounds with loadSfx. The checkers are scored in
+// sess, which may be nil; see Session for when it is finished.
+func LoadScene(path string, w Window, loadSfx func(filename string) SoundEffect, sess *Session) (Element, error) {
+	b, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sf sceneFile
+	if err := json.Unmarshal(b, &sf); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if sf.Scene == nil {
+		return nil, fmt.Errorf("%s: no scene", path)
+	}
+
+	if sess == nil {
+		sess = &Session{}
+	}
+	sess.Started = time.Now()
+
+	sb := &sceneBuilder{
+		w: w, sounds: map[string]SoundEffect{}, sess: sess,
+		words: map[string][]string{}, rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+		arranged: map[*elementSpec]*Rect{},
+	}
+	for name, file := range sf.Sounds {
+		sb.sounds[name] = loadSfx(file)
+	}
+
+	elm, err := sb.build(sf.Scene)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	// a discrete root is the lessons; going back to the first is a
+	// new learner
+	if dp, ok := elm.(*DiscretePlayer); ok {
+		dp.onAdvance = func(index int) {
+			if index == 0 {
+				sess.finish()
+			}
+			if sess.Progress != nil {
+				sess.Progress.lesson(index)
+			}
+		}
+		dp.onReset = sess.finish
+		if sess.Progress != nil {
+			sess.Progress.track(dp)
+		}
+	}
+	sc := &Scene{elm, sb.placed}
+	// progress to resume is only known now it is tracked
+	if err := sc.place(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return sc, nil
+}
+
+// Scene is the root element of a loaded scene, which remembers how the
+// rects of its elements were worked out so they can be again when the
+// window changes size.
+type Scene struct {
+	Element
+	placed []func() error // each works some rects out again, in place
+}
+
+// Reflow works out every rect in the scene again for the window as it
+// is now, and reflows the elements in them. If a rect can't be worked
+// out for the window's new size, it returns why and reflows nothing.
+func (sc *Scene) Reflow() error {
+	if err := sc.place(); err != nil {
+		return err
+	}
+	reflow(sc.Element)
+	return nil
+}
+
+// Reset resets the scene, and works its rects out again for what there
+// is to show now, like a learner's progress to resume.
+func (sc *Scene) Reset() {
+	sc.Element.Reset()
+	// the window is the size the rects were last worked out for, so
+	// they can't fail to be now
+	sc.place()
+}
+
+// place works out every rect again, stopping at the first that can't
+// be.
+func (sc *Scene) place() error {
+	for _, place := range sc.placed {
+		if err := place(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sceneBuilder struct {
+	w        Window
+	sounds   map[string]SoundEffect
+	sess     *Session
+	checkers int
+	ids      map[string]bool     // the IDs of the checkers built so far
+	words    map[string][]string // quiz word lists by path
+	rnd      *rand.Rand
+	placed   []func() error
+	arranged map[*elementSpec]*Rect // rects given by a player's layout
+
+	// depth is how many elements deep the builder is; the children of
+	// the root are lessons, and lesson is the one being built
+	depth  int
+	lesson int
+}
+
+func (sb *sceneBuilder) build(es *elementSpec) (Element, error) {
+	sb.depth++
+	defer func() { sb.depth-- }()
+
+	switch es.Type {
+	case "discrete", "sequential", "concurrent":
+		if err := sb.arrange(es); err != nil {
+			return nil, err
+		}
+		elms, err := sb.buildAll(es.Children)
+		if err != nil {
+			return nil, err
+		}
+		switch es.Type {
+		case "discrete":
+			return NewDiscretePlayer(elms), nil
+		case "sequential":
+			return NewSequentialPlayer(elms), nil
+		}
+		return NewConcurrentPlayer(elms), nil
+
+	case "typewritter", "slowtext":
+		if err := checkMarkup(es.Text); err != nil {
+			return nil, err
+		}
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		dict, err := sb.dict(es)
+		if err != nil {
+			return nil, err
+		}
+		if es.Type == "slowtext" {
+			return NewSlowText(es.Text, r, dict, sb.w), nil
+		}
+		click, err := sb.sound(es.Click, "click")
+		if err != nil {
+			return nil, err
+		}
+		ding, err := sb.sound(es.Ding, "ding")
+		if err != nil {
+			return nil, err
+		}
+		return NewTypewritter(es.Text, r, dict, sb.w, click, ding), nil
+
+	case "hovertext":
+		if err := checkMarkup(es.Text); err != nil {
+			return nil, err
+		}
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		dict, err := sb.dict(es)
+		if err != nil {
+			return nil, err
+		}
+		return NewHoverText(es.Text, r, dict, sb.w), nil
+
+	case "checker":
+		if es.Input == nil || es.Right == nil || es.Wrong == nil {
+			return nil, fmt.Errorf("checker needs input, right and wrong")
+		}
+		in, err := sb.build(es.Input)
+		if err != nil {
+			return nil, err
+		}
+		chk, ok := in.(Checkable)
+		if !ok {
+			return nil, fmt.Errorf("checker input %q is not checkable", es.Input.Type)
+		}
+		right, err := sb.build(es.Right)
+		if err != nil {
+			return nil, err
+		}
+		wrong, err := sb.build(es.Wrong)
+		if err != nil {
+			return nil, err
+		}
+		chkr := NewChecker(chk, es.Correct, right, wrong)
+		if err := sb.match(es, chkr); err != nil {
+			return nil, err
+		}
+		if err := sb.score(es, chkr); err != nil {
+			return nil, err
+		}
+		return chkr, nil
+
+	case "quiz":
+		if es.Right == nil || es.Wrong == nil {
+			return nil, fmt.Errorf("quiz needs right and wrong")
+		}
+		level, ok := quizLevels[es.Level]
+		if !ok {
+			return nil, fmt.Errorf("unknown quiz level %q", es.Level)
+		}
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		if r.h < 3 {
+			return nil, fmt.Errorf("quiz needs a rect at least 3 high")
+		}
+		wordsPath := es.Words
+		if wordsPath == "" {
+			wordsPath = "dict/quiz.txt"
+		}
+		words, err := sb.quizWords(wordsPath)
+		if err != nil {
+			return nil, err
+		}
+		questions := quizQuestions(words, level, defaultTranslator)
+		if len(questions) == 0 {
+			return nil, fmt.Errorf("no %s quiz questions in %s", es.Level, wordsPath)
+		}
+		right, err := sb.build(es.Right)
+		if err != nil {
+			return nil, err
+		}
+		wrong, err := sb.build(es.Wrong)
+		if err != nil {
+			return nil, err
+		}
+		encode, err := encodes(es)
+		if err != nil {
+			return nil, err
+		}
+		q := NewQuiz(defaultTranslator, questions, level.Hints, encode, r, sb.w, right, wrong, rand.New(rand.NewSource(sb.rnd.Int63())))
+		if err := sb.match(es, q.chkr); err != nil {
+			return nil, err
+		}
+		if err := sb.chars(es, q.input); err != nil {
+			return nil, err
+		}
+		if err := sb.score(es, q.chkr); err != nil {
+			return nil, err
+		}
+		return q, nil
+
+	case "options":
+		if len(es.Options) == 0 {
+			return nil, fmt.Errorf("options needs at least one option")
+		}
+		for _, option := range es.Options {
+			if err := checkMarkup(option); err != nil {
+				return nil, err
+			}
+		}
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		return NewOptions(es.Options, r, sb.w), nil
+
+	case "textinput":
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		if es.Max < 0 {
+			return nil, fmt.Errorf("textinput max can't be negative")
+		}
+		ti := NewTextInput(r, sb.w)
+		ti.max = es.Max
+		if err := sb.chars(es, ti); err != nil {
+			return nil, err
+		}
+		return ti, nil
+
+	case "translator":
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		if r.h < 3 {
+			return nil, fmt.Errorf("translator needs a rect at least 3 high")
+		}
+		return NewTranslatorPad(defaultTranslator, r, sb.w), nil
+
+	case "waitfornext":
+		return NewWaitForNext(), nil
+
+	case "resume":
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		return NewResumePrompt(es.Text, r, sb.w, sb.sess.Progress), nil
+
+	case "results":
+		r, err := sb.rect(es)
+		if err != nil {
+			return nil, err
+		}
+		return NewResultsSummary(&sb.sess.Score, sb.lesson, r, sb.w), nil
+	}
+
+	return nil, fmt.Errorf("unknown element type %q", es.Type)
+}
+
+// score makes chkr count towards the session's score and progress.
+// Progress is saved by ID, so no two checkers may share one.
+func (sb *sceneBuilder) score(es *elementSpec, chkr *Checker) error {
+	sb.checkers++
+	id := es.ID
+	if id == "" {
+		id = fmt.Sprintf("checker%d", sb.checkers)
+	}
+	if sb.ids[id] {
+		return fmt.Errorf("%s: id %q is used by another checker", es.Type, id)
+	}
+	if sb.ids == nil {
+		sb.ids = map[string]bool{}
+	}
+	sb.ids[id] = true
+	lesson := sb.lesson
+	sb.sess.Score.add(id, lesson)
+	record := func() {
+		cr := chkr.Result()
+		cr.ID, cr.Lesson = id, lesson
+		sb.sess.Score.record(cr)
+	}
+	chkr.onWrong = record
+	chkr.onRight = func() {
+		record()
+		if sb.sess.Progress != nil {
+			sb.sess.Progress.completed(id)
+		}
+	}
+	return nil
+}
+
+// match sets how chkr matches answers, and where it shows the mistakes
+// in wrong ones.
+func (sb *sceneBuilder) match(es *elementSpec, chkr *Checker) error {
+	name := es.Match
+	if name == "" {
+		name = "words"
+	}
+	m, ok := matchers[name]
+	if !ok {
+		return fmt.Errorf("unknown match %q", es.Match)
+	}
+	encode, err := encodes(es)
+	if err != nil {
+		return err
+	}
+
+	wm, ok := m.(WordMatcher)
+	switch {
+	case ok:
+		switch es.Marks {
+		case "", "strict":
+		case "loose":
+			wm.LooseMarks = true
+		default:
+			return fmt.Errorf("unknown marks %q", es.Marks)
+		}
+		if encode {
+			wm.Code = defaultTranslator
+		}
+		m = wm
+	case es.Marks != "" || encode:
+		return fmt.Errorf("%s match can't have marks or encode", name)
+	}
+	chkr.matcher = m
+
+	if es.Feedback != nil {
+		r, err := sb.place(es.Feedback)
+		if err != nil {
+			return err
+		}
+		chkr.feedback, chkr.w = r, sb.w
+	}
+	return nil
+}
+
+// encodes reports whether es's direction is encode.
+func encodes(es *elementSpec) (bool, error) {
+	switch es.Direction {
+	case "", "decode":
+		return false, nil
+	case "encode":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown direction %q", es.Direction)
+}
+
+// chars shows clickable Navajo letters for ti, if es asks for them.
+func (sb *sceneBuilder) chars(es *elementSpec, ti *TextInput) error {
+	if es.Chars == nil {
+		return nil
+	}
+	r, err := sb.place(es.Chars)
+	if err != nil {
+		return err
+	}
+	ti.showChars(r)
+	return nil
+}
+
+// arrange gives rects to the children of es by its layout, if it has
+// one, for them to be built with.
+func (sb *sceneBuilder) arrange(es *elementSpec) error {
+	if es.Layout == nil {
+		return nil
+	}
+	newArr, ok := arrangements[es.Layout.Type]
+	if !ok {
+		return fmt.Errorf("unknown layout %q", es.Layout.Type)
+	}
+	arr := newArr(es.Layout.Gap)
+	area := es.Layout.Rect
+	if area == nil {
+		area = &rectSpec{X: "0", Y: "0", H: "height-2"}
+	}
+
+	var sizes []sizer
+	var rects []*Rect
+	for _, child := range es.Children {
+		size, err := sb.size(child)
+		if err != nil {
+			return err
+		}
+		if size == nil {
+			continue
+		}
+		r := &Rect{}
+		sb.arranged[child] = r
+		sizes = append(sizes, size)
+		rects = append(rects, r)
+	}
+
+	place := func() error {
+		ar, err := area.toRect(sb.w)
+		if err != nil {
+			return err
+		}
+		for i, r := range arr.Arrange(*ar, sizes) {
+			*rects[i] = r
+		}
+		return nil
+	}
+	if err := place(); err != nil {
+		return err
+	}
+	sb.placed = append(sb.placed, place)
+	return nil
+}
+
+// size is how big the element es describes is, for a layout to give it
+// a rect. A rect without absolute says how big, and any x and y in it
+// are ignored; otherwise the size is that of its text. Elements with
+// neither, or with an absolute rect, aren't laid out and have no size.
+func (sb *sceneBuilder) size(es *elementSpec) (sizer, error) {
+	if es.Rect != nil {
+		if es.Rect.Absolute {
+			return nil, nil
+		}
+		rs := es.Rect
+		wh := func() (w, h int, err error) {
+			vars := map[string]int{"width": sb.w.GetWidth(), "height": sb.w.GetHeight()}
+			if w, err = rs.W.eval(vars); err != nil {
+				return
+			}
+			h, err = rs.H.eval(vars)
+			return
+		}
+		if _, _, err := wh(); err != nil {
+			return nil, err
+		}
+		return func(maxW int) box {
+			w, h, _ := wh()
+			if w <= 0 || w > maxW {
+				w = maxW
+			}
+			return box{w, h}
+		}, nil
+	}
+
+	switch es.Type {
+	case "typewritter", "slowtext", "hovertext":
+		return textSize(es.Text), nil
+	case "resume":
+		// it takes no room until there is progress to resume
+		size, pt := textSize(es.Text), sb.sess.Progress
+		return func(maxW int) box {
+			if !pt.CanResume() {
+				return box{}
+			}
+			return size(maxW)
+		}, nil
+	case "options":
+		return optionsSize(es.Options), nil
+	}
+	return nil, nil
+}
+
+// quizWords loads a quiz word list once per scene.
+func (sb *sceneBuilder) quizWords(path string) ([]string, error) {
+	if words, ok := sb.words[path]; ok {
+		return words, nil
+	}
+	words, err := LoadQuizWords(path)
+	if err != nil {
+		return nil, err
+	}
+	sb.words[path] = words
+	return words, nil
+}
+
+func (sb *sceneBuilder) buildAll(specs []*elementSpec) ([]Element, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("player needs at least one child")
+	}
+
+	elms := make([]Element, len(specs))
+	for i, es := range specs {
+		if sb.depth == 1 {
+			sb.lesson = i
+		}
+		elm, err := sb.build(es)
+		if err != nil {
+			return nil, err
+		}
+		elms[i] = elm
+	}
+	return elms, nil
+}
+
+func (sb *sceneBuilder) rect(es *elementSpec) (*Rect, error) {
+	if r, ok := sb.arranged[es]; ok {
+		return r, nil
+	}
+	if es.Rect == nil {
+		return nil, fmt.Errorf("%s needs a rect", es.Type)
+	}
+	return sb.place(es.Rect)
+}
+
+// place works out the rect rs is for, and remembers it for reflowing.
+func (sb *sceneBuilder) place(rs *rectSpec) (*Rect, error) {
+	r, err := rs.toRect(sb.w)
+	if err != nil {
+		return nil, err
+	}
+	sb.placed = append(sb.placed, func() error {
+		nr, err := rs.toRect(sb.w)
+		if err != nil {
+			return err
+		}
+		*r = *nr
+		return nil
+	})
+	return r, nil
+}
+
+// sound looks up a sound by name, falling back to def if name is
+// empty. A missing default sound is not an error; the element will
+// just be silent.
+func (sb *sceneBuilder) sound(name, def string) (SoundEffect, error) {
+	if name == "" {
+		if sfx, ok := sb.sounds[def]; ok {
+			return sfx, nil
+		}
+		return NullSfx{}, nil
+	}
+	sfx, ok := sb.sounds[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sound %q", name)
+	}
+	return sfx, nil
+}
+
+// dict returns the named dictionary, or the inline replacements if
+// the spec has any.
+func (sb *sceneBuilder) dict(es *elementSpec) (ReplaceMap, error) {
+	if len(es.Replace) > 0 {
+		rm := ReplaceMap{}
+		for k, v := range es.Replace {
+			s := normal
+			if v.Style != "" {
+				var ok bool
+				if s, ok = parseStyle(v.Style); !ok {
+					return nil, fmt.Errorf("%s: unknown style %q for %q", es.Type, v.Style, k)
+				}
+			}
+			rm[strings.ToLower(k)] = translation{v.Text, s}
+		}
+		return rm, nil
+	}
+
+	if es.Dict == "" {
+		return ReplaceMap{}, nil
+	}
+	rm, ok := dictionaries[es.Dict]
+	if !ok {
+		return nil, fmt.Errorf("unknown dictionary %q", es.Dict)
+	}
+	return rm, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// countSfx counts how many times it is played.
+type countSfx struct{ plays *int }
+
+func (c countSfx) Play() { *c.plays++ }
+
+// writeScene writes a scene file to a temporary directory and returns
+// its path.
+func writeScene(t *testing.T, scene string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := os.WriteFile(path, []byte(scene), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSceneMissingDefaultSound(t *testing.T) {
+	for _, sounds := range []string{
+		`{}`,
+		`{"ding": "ding.wav"}`,
+		`{"click": "click.wav"}`,
+	} {
+		path := writeScene(t, `{
+			"sounds": `+sounds+`,
+			"scene": {"type": "typewritter", "text": "hi there", "rect": {"x": 0, "y": 0, "h": 1}}
+		}`)
+		plays := 0
+		w := NewHeadlessWindow(40, 5)
+		scene, err := LoadScene(path, w, func(string) SoundEffect { return countSfx{&plays} }, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", sounds, err)
+		}
+
+		// typed out, then again after a reset; neither may play a
+		// sound the scene doesn't have
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 20; j++ {
+				scene.Update(nil)
+			}
+			if !scene.Done() {
+				t.Errorf("%s: not done typing", sounds)
+			}
+			scene.Reset()
+		}
+		if sounds == `{}` && plays != 0 {
+			t.Errorf("%s: played %d sounds", sounds, plays)
+		}
+	}
+}
+
+// titleRows are the rows of the title screen's centred text, and of the
+// resume prompt, -1 if it isn't shown.
+func titleRows(t *testing.T, dump string) (top, bottom, resume int) {
+	t.Helper()
+	top, bottom, resume = -1, -1, -1
+	for y, line := range strings.Split(dump, "\n") {
+		switch {
+		case strings.Contains(line, "[TOP SECRET]"):
+			top = y
+		case strings.Contains(line, "Press [SPACE] to start!"):
+			bottom = y
+		case strings.Contains(line, "or [R] to resume!"):
+			resume = y
+		}
+	}
+	if top < 0 || bottom < 0 {
+		t.Fatalf("no title on screen:\n%s", dump)
+	}
+	return top, bottom, resume
+}
+
+func TestTitleResumeRow(t *testing.T) {
+	d, err := LoadDictionary("dict/master.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseDictionary(d)
+
+	pf := &ProgressFile{filepath.Join(t.TempDir(), "progress.json")}
+	play := func(script string) *HeadlessWindow {
+		t.Helper()
+		pt, err := NewProgressTracker(pf, "ada")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewHeadlessWindow(79, 20)
+		scene, err := LoadScene("scenes/main.json", w, silentSfx, &Session{Progress: pt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rp := NewReplay(scene, w, "")
+		if err := rp.Run(t.Name(), strings.NewReader(script)); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range rp.Failures {
+			t.Error(f)
+		}
+		return w
+	}
+
+	// nothing to resume: no room is kept for it, and the title is in
+	// the middle of the rows below the overlay
+	w := play("tick 100")
+	top, bottom, resume := titleRows(t, w.Dump())
+	if resume >= 0 {
+		t.Errorf("resume prompt shown with no progress")
+	}
+	if above, below := top-1, 19-bottom; above-below > 1 || below-above > 1 {
+		t.Errorf("title rows %d to %d of 1 to 19 aren't centred", top, bottom)
+	}
+
+	// once the learner has moved on, going back to the title offers to
+	// resume, right below the rest
+	w = play(`
+		tick 100
+		key space
+		tick 500
+		key space
+		tick
+		key reset
+		tick 100
+	`)
+	if _, bottom, resume := titleRows(t, w.Dump()); resume != bottom+1 {
+		t.Errorf("resume prompt on row %d, want %d:\n%s", resume, bottom+1, w.Dump())
+	}
+
+	// as it does for a learner coming back
+	w = play("tick 100")
+	if _, bottom, resume := titleRows(t, w.Dump()); resume != bottom+1 {
+		t.Errorf("resume prompt on row %d, want %d:\n%s", resume, bottom+1, w.Dump())
+	}
+}
+
+func TestSceneReplaceStyle(t *testing.T) {
+	for style, want := range map[string]string{
+		`"t2ne"`:  "",
+		`""`:      "",
+		`"t2en"`:  `hovertext: unknown style "t2en" for "ship"`,
+		`"T2NE"`:  `hovertext: unknown style "T2NE" for "ship"`,
+		`"popup"`: "",
+	} {
+		path := writeScene(t, `{"scene": {
+			"type": "hovertext", "text": "a {ship}", "rect": {"x": 0, "y": 0, "h": 1},
+			"replace": {"ship": {"text": "lóóʼ", "style": `+style+`}}
+		}}`)
+		_, err := LoadScene(path, NewHeadlessWindow(40, 5), silentSfx, nil)
+		if want == "" && err != nil || want != "" && (err == nil || err.Error() != path+": "+want) {
+			t.Errorf("style %s: LoadScene = %v, want %q", style, err, want)
+		}
+	}
+}
+
+func TestSceneReflowError(t *testing.T) {
+	// fine at the height it's loaded at, but not one 5 rows taller
+	path := writeScene(t, `{"scene": {
+		"type": "slowtext", "text": "hi", "rect": {"x": "10/(height-25)", "y": 0, "h": 1}
+	}}`)
+	w := NewHeadlessWindow(79, 20)
+	scene, err := LoadScene(path, w, silentSfx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := NewReplay(scene, w, "")
+	err = rp.Run("reflow", strings.NewReader("tick\nresize 79 24\ntick\nresize 79 25\ntick"))
+	if want := `reflow:4: laying the scene out at 79x25: expression "10/(height-25)": division by zero`; err == nil || err.Error() != want {
+		t.Errorf("Run = %v, want %q", err, want)
+	}
+}
+
+func TestSceneCheckerIDs(t *testing.T) {
+	checker := func(id string) string {
+		return `{"type": "checker", "id": "` + id + `",
+			"input": {"type": "textinput", "rect": {"x": 0, "y": 0, "h": 1}},
+			"correct": ["a"],
+			"right": {"type": "slowtext", "text": "yes", "rect": {"x": 0, "y": 1, "h": 1}},
+			"wrong": {"type": "slowtext", "text": "no", "rect": {"x": 0, "y": 1, "h": 1}}}`
+	}
+	for _, tt := range []struct {
+		ids  [2]string
+		want string
+	}{
+		{[2]string{"one", "two"}, ""},
+		{[2]string{"", ""}, ""},
+		{[2]string{"one", "one"}, `checker: id "one" is used by another checker`},
+		// the second one's ID is made up, the same as the first's
+		{[2]string{"checker2", ""}, `checker: id "checker2" is used by another checker`},
+	} {
+		path := writeScene(t, `{"scene": {"type": "sequential", "children": [`+checker(tt.ids[0])+`, `+checker(tt.ids[1])+`]}}`)
+		_, err := LoadScene(path, NewHeadlessWindow(40, 5), silentSfx, nil)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || err.Error() != path+": "+tt.want) {
+			t.Errorf("IDs %q: LoadScene = %v, want %q", tt.ids, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// CheckResult is how a learner did on one Checker.
+type CheckResult struct {
+	ID       string
+	Lesson   int // the child of the scene's root the checker is in
+	Answered bool
+
+	Attempts int           // answers given, the last of them right
+	Wrong    []string      // the wrong answers, in the order given
+	Answer   string        // the right answer
+	Time     time.Duration // from the question being asked to the right answer
+	Credit   float64       // the most of any answer that was right, from 0 to 1
+}
+
+// pointsPerQuestion is what a question answered at the first try earns.
+const pointsPerQuestion = 3
+
+// Points is 3 for a right first try, 2 for a second and 1 after that.
+func (cr CheckResult) Points() int {
+	if !cr.Answered {
+		return 0
+	}
+	if cr.Attempts >= pointsPerQuestion {
+		return 1
+	}
+	return pointsPerQuestion + 1 - cr.Attempts
+}
+
+// Scorecard is the CheckResults of one session, with a result for every
+// checker in the scene in the order they are played.
+type Scorecard struct {
+	Results []CheckResult
+}
+
+// add makes a result for the checker id in lesson, unanswered until
+// recorded.
+func (sc *Scorecard) add(id string, lesson int) {
+	sc.Results = append(sc.Results, CheckResult{ID: id, Lesson: lesson})
+}
+
+func (sc *Scorecard) record(cr CheckResult) {
+	for i := range sc.Results {
+		if sc.Results[i].ID == cr.ID {
+			sc.Results[i] = cr
+			return
+		}
+	}
+	sc.Results = append(sc.Results, cr)
+}
+
+// before is the results of the checkers in lessons before lesson.
+func (sc *Scorecard) before(lesson int) *Scorecard {
+	b := &Scorecard{}
+	for _, cr := range sc.Results {
+		if cr.Lesson < lesson {
+			b.Results = append(b.Results, cr)
+		}
+	}
+	return b
+}
+
+// clear forgets every answer, for the next learner.
+func (sc *Scorecard) clear() {
+	for i, cr := range sc.Results {
+		sc.Results[i] = CheckResult{ID: cr.ID, Lesson: cr.Lesson}
+	}
+}
+
+// Score is the points earned of the most that could have been.
+func (sc *Scorecard) Score() (points, max int) {
+	for _, cr := range sc.Results {
+		points += cr.Points()
+	}
+	return points, len(sc.Results) * pointsPerQuestion
+}
+
+// Lines are the scorecard as a table, ending with the score.
+func (sc *Scorecard) Lines() []string {
+	lines := []string{fmt.Sprintf("%-12s %-9s %-6s %s", "Question", "Attempts", "Time", "Wrong answers")}
+	for _, cr := range sc.Results {
+		if !cr.Answered {
+			lines = append(lines, fmt.Sprintf("%-12s %-9s %-6s", cr.ID, "-", "-"))
+			continue
+		}
+
+		wrong := ""
+		for i, a := range cr.Wrong {
+			if i > 0 {
+				wrong += ", "
+			}
+			wrong += fmt.Sprintf("%q", a)
+		}
+		secs := int(cr.Time.Round(time.Second) / time.Second)
+		lines = append(lines, fmt.Sprintf("%-12s %-9d %-6s %s", cr.ID, cr.Attempts, fmt.Sprintf("%d:%02d", secs/60, secs%60), wrong))
+	}
+
+	points, max := sc.Score()
+	return append(lines, fmt.Sprintf("Score: %d/%d", points, max))
+}
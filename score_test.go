@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckResultPoints(t *testing.T) {
+	for _, tt := range []struct {
+		cr   CheckResult
+		want int
+	}{
+		{CheckResult{}, 0},
+		// unanswered, though tried
+		{CheckResult{Attempts: 2, Wrong: []string{"a", "b"}}, 0},
+		{CheckResult{Answered: true, Attempts: 1}, 3},
+		{CheckResult{Answered: true, Attempts: 2}, 2},
+		{CheckResult{Answered: true, Attempts: 3}, 1},
+		{CheckResult{Answered: true, Attempts: 10}, 1},
+	} {
+		if got := tt.cr.Points(); got != tt.want {
+			t.Errorf("%+v: Points = %d, want %d", tt.cr, got, tt.want)
+		}
+	}
+}
+
+func TestScorecardRecord(t *testing.T) {
+	sc := &Scorecard{}
+	sc.add("one", 1)
+	sc.add("two", 2)
+
+	// a wrong answer, then the right one, replace the result
+	sc.record(CheckResult{ID: "one", Lesson: 1, Attempts: 1, Wrong: []string{"x"}})
+	sc.record(CheckResult{ID: "one", Lesson: 1, Answered: true, Attempts: 2, Wrong: []string{"x"}})
+	// one the scene didn't add goes at the end
+	sc.record(CheckResult{ID: "three", Lesson: 3, Answered: true, Attempts: 1})
+	want := []CheckResult{
+		{ID: "one", Lesson: 1, Answered: true, Attempts: 2, Wrong: []string{"x"}},
+		{ID: "two", Lesson: 2},
+		{ID: "three", Lesson: 3, Answered: true, Attempts: 1},
+	}
+	if !reflect.DeepEqual(sc.Results, want) {
+		t.Fatalf("Results = %+v, want %+v", sc.Results, want)
+	}
+	if points, max := sc.Score(); points != 5 || max != 9 {
+		t.Errorf("Score = %d/%d, want 5/9", points, max)
+	}
+
+	for lesson, n := range map[int]int{0: 0, 1: 0, 2: 1, 3: 2, 4: 3} {
+		if got := sc.before(lesson).Results; len(got) != n || n > 0 && !reflect.DeepEqual(got, want[:n]) {
+			t.Errorf("before(%d) = %+v, want %+v", lesson, got, want[:n])
+		}
+	}
+
+	sc.clear()
+	want = []CheckResult{{ID: "one", Lesson: 1}, {ID: "two", Lesson: 2}, {ID: "three", Lesson: 3}}
+	if !reflect.DeepEqual(sc.Results, want) {
+		t.Errorf("after clear, Results = %+v, want %+v", sc.Results, want)
+	}
+}
+
+func TestScorecardLines(t *testing.T) {
+	header := "Question     Attempts  Time   Wrong answers"
+	for _, tt := range []struct {
+		results []CheckResult
+		want    []string
+	}{
+		{nil, []string{header, "Score: 0/0"}},
+		{[]CheckResult{{ID: "type1"}}, []string{
+			header,
+			"type1        -         -     ",
+			"Score: 0/3",
+		}},
+		{[]CheckResult{
+			{ID: "type1", Answered: true, Attempts: 1, Time: 4400 * time.Millisecond},
+			{ID: "type2", Answered: true, Attempts: 3, Wrong: []string{"bomber", `say "hi"`}, Time: 75 * time.Second},
+		}, []string{
+			header,
+			"type1        1         0:04   ",
+			`type2        3         1:15   "bomber", "say \"hi\""`,
+			"Score: 4/6",
+		}},
+	} {
+		sc := &Scorecard{tt.results}
+		if got := sc.Lines(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lines = %q, want %q", got, tt.want)
+		}
+	}
+}
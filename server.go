@@ -0,0 +1,196 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Ahoys123/tcell"
+	"github.com/Ahoys123/tcell/terminfo"
+)
+
+// Server serves the tutorial over telnet. Every connection gets its own
+// screen, scene and run loop, so learners don't see each other.
+type Server struct {
+	ScenePath string
+	Term      *terminfo.Terminfo
+
+	// Reports is the directory session reports are written to, "" for
+	// none. Learners are asked their name when they connect; those who
+	// don't give one are named by their address and connection number.
+	Reports string
+
+	// MaxSessions is the most connections served at once; any more are
+	// turned away. 0 means no limit.
+	MaxSessions int
+
+	mu       sync.Mutex
+	sessions int
+	served   int // connections accepted, to number them by
+}
+
+// Serve accepts connections on ln until it fails.
+func (srv *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+
+		n, ok := srv.acquire()
+		if !ok {
+			fmt.Fprint(conn, "Sorry, all seats are taken. Please try again later.\r\n")
+			conn.Close()
+			log.Printf("%s: turned away, %d sessions running", conn.RemoteAddr(), srv.MaxSessions)
+			continue
+		}
+
+		go func() {
+			defer srv.release()
+			log.Printf("%s: connected", conn.RemoteAddr())
+			if err := srv.session(conn, n); err != nil {
+				log.Printf("%s: %v", conn.RemoteAddr(), err)
+			}
+			log.Printf("%s: disconnected", conn.RemoteAddr())
+		}()
+	}
+}
+
+// acquire takes a seat for a connection, and gives its number.
+func (srv *Server) acquire() (int, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.MaxSessions > 0 && srv.sessions >= srv.MaxSessions {
+		return 0, false
+	}
+	srv.sessions++
+	srv.served++
+	return srv.served, true
+}
+
+func (srv *Server) release() {
+	srv.mu.Lock()
+	srv.sessions--
+	srv.mu.Unlock()
+}
+
+// session plays the scene on conn, the nth connection, until the
+// learner quits or hangs up. Closing the screen closes conn.
+func (srv *Server) session(conn net.Conn, n int) error {
+	tty := newTelnetTty(conn)
+	learner, err := askName(tty)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if learner == "" {
+		host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			host = conn.RemoteAddr().String()
+		}
+		learner = fmt.Sprintf("%s#%d", host, n)
+	}
+	log.Printf("%s: learner %q", conn.RemoteAddr(), learner)
+
+	s, err := tcell.NewTerminfoScreenFromTtyTerminfo(tty, srv.Term)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	w, err := NewTermWindowFromScreen(s, 79, 20)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	sess := &Session{Learner: learner, Reports: srv.Reports}
+
+	// sound would play on the server, not for the learner
+	scene, err := LoadScene(srv.ScenePath, w, silentSfx, sess)
+	if err != nil {
+		w.Fini()
+		return err
+	}
+
+	evChan, cquit := w.ChannelEvents()
+	err = run(w, scene, evChan, cquit)
+	sess.finish()
+	if err != nil {
+		return err
+	}
+	return sess.Err
+}
+
+// maxName is the longest name askName takes, in bytes.
+const maxName = 40
+
+// askName asks the learner for their name before the screen is started,
+// while their client still sends a line at a time. Backspaces typed in
+// clients that send each key are honoured. It gives "" if they just
+// press enter. Whatever was sent after the name is left to be read by
+// the session.
+func askName(tty *telnetTty) (string, error) {
+	if _, err := tty.Write([]byte("What is your name? ")); err != nil {
+		return "", err
+	}
+	var name []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := tty.Read(buf)
+		for i, b := range buf[:n] {
+			switch {
+			case b == '\r' || b == '\n':
+				tty.unread = append(tty.unread, buf[i+1:n]...)
+				return strings.TrimSpace(strings.ToValidUTF8(string(name), "")), nil
+			case b == '\b' || b == 127:
+				_, size := utf8.DecodeLastRune(name)
+				name = name[:len(name)-size]
+			case b >= ' ' && len(name) < maxName:
+				name = append(name, b)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func init() {
+	commands["serve"] = serveCmd
+}
+
+// serveCmd runs a Server until it fails.
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":2323", "address to listen on")
+	scenePath := fs.String("scene", *sceneFlag, "scene file to play")
+	maxSessions := fs.Int("max", 30, "most sessions at once, 0 for no limit")
+	term := fs.String("term", "xterm-256color", "terminal type of the clients")
+	reports := fs.String("reports", *reportsFlag, "directory to write a report of each session to")
+	fs.Parse(args)
+
+	ti, err := tcell.LookupTerminfo(*term)
+	if err != nil {
+		return fmt.Errorf("terminal %q: %v", *term, err)
+	}
+
+	// catch a broken scene now rather than on the first connection
+	if _, err := LoadScene(*scenePath, NewHeadlessWindow(79, 20), silentSfx, nil); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("serving %s on %s", *scenePath, ln.Addr())
+
+	srv := &Server{ScenePath: *scenePath, Term: ti, Reports: *reports, MaxSessions: *maxSessions}
+	return srv.Serve(ln)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+)
+
+// Session is one learner's time with a scene. It finishes when the
+// scene goes back to the title, is reset, or the program ends; a
+// session with any answers is then reported, and the next one starts.
+type Session struct {
+	Learner  string
+	Progress *ProgressTracker // nil to not save progress
+	Reports  string           // directory reports are written to, "" for none
+
+	Score   Scorecard
+	Started time.Time
+
+	// Err is the first error writing a report, reported once the
+	// screen is closed.
+	Err error
+}
+
+// finish reports the session if anything was answered, and starts the
+// next one.
+func (s *Session) finish() {
+	if s.Reports != "" && s.answered() {
+		rep := NewReport(s, time.Now())
+		if err := rep.WriteFiles(s.Reports); err != nil && s.Err == nil {
+			s.Err = err
+		}
+	}
+
+	s.Score.clear()
+	s.Started = time.Now()
+}
+
+func (s *Session) answered() bool {
+	for _, cr := range s.Score.Results {
+		if cr.Answered {
+			return true
+		}
+	}
+	return false
+}
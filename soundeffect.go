@@ -1,10 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
+	"sync"
 	"time"
 
 	"github.com/faiface/beep"
@@ -12,52 +11,58 @@ import (
 	"github.com/faiface/beep/wav"
 )
 
-var inited bool = false
-
 type SoundEffect interface {
 	Play()
 }
 
+// NullSfx is a SoundEffect that plays nothing.
+type NullSfx struct{}
+
+func (NullSfx) Play() {}
+
+// silentSfx loads every sound as a NullSfx, for when sound is off.
+func silentSfx(string) SoundEffect {
+	return NullSfx{}
+}
+
+// sfxErr is why the first sound that failed to load is silent. It is
+// reported once the screen is closed.
+var sfxErr error
+
 type BeepSfx struct {
 	buffer *beep.Buffer
 }
 
-func NewBeepSfx(filename string) (sfx *BeepSfx) {
-	f, err := getContent(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer f.Close()
+var (
+	speakerOnce sync.Once
+	speakerErr  error
+)
 
-	streamer, format, err := wav.Decode(f)
+// NewBeepSfx loads a wav file to play through the speaker, which is
+// started by the first sound loaded. It fails if the speaker can't be,
+// e.g. on a machine with no sound card.
+func NewBeepSfx(filename string) (*BeepSfx, error) {
+	b, err := readFile(filename)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	if !inited {
-		speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
-		inited = true
+		return nil, err
 	}
 
-	sfx = &BeepSfx{beep.NewBuffer(format)}
-	sfx.buffer.Append(streamer)
-	streamer.Close()
-
-	return sfx
-}
-
-func getContent(url string) (io.ReadCloser, error) {
-	resp, err := http.Get(url)
+	streamer, format, err := wav.Decode(bytes.NewReader(b))
 	if err != nil {
-		return nil, fmt.Errorf("GET error: %v", err)
+		return nil, fmt.Errorf("%s: %v", filename, err)
 	}
+	defer streamer.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status error: %v", resp.StatusCode)
+	speakerOnce.Do(func() {
+		speakerErr = speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
+	})
+	if speakerErr != nil {
+		return nil, speakerErr
 	}
 
-	return resp.Body, nil
+	sfx := &BeepSfx{beep.NewBuffer(format)}
+	sfx.buffer.Append(streamer)
+	return sfx, nil
 }
 
 func (sfx *BeepSfx) Play() {
@@ -0,0 +1,205 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// telnet commands and options, from RFC 854, 857, 858 and 1073.
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	telnetEcho     = 1
+	telnetSGA      = 3
+	telnetNAWS     = 31
+	telnetLinemode = 34
+)
+
+type telnetState uint8
+
+const (
+	telnetData telnetState = iota
+	telnetCR
+	telnetCommand
+	telnetOption
+	telnetSub
+	telnetSubIAC
+)
+
+// telnetTty is a tcell.Tty on a telnet connection. It puts the client
+// into character at a time mode, asks for its window size and strips
+// telnet commands out of what is read.
+type telnetTty struct {
+	conn net.Conn
+
+	mu            sync.Mutex
+	width, height int
+	resize        func()
+	started       bool
+
+	state telnetState
+	sub   []byte
+
+	unread []byte // data read before the screen was started, read first
+}
+
+func newTelnetTty(conn net.Conn) *telnetTty {
+	return &telnetTty{conn: conn, width: 80, height: 24}
+}
+
+func (t *telnetTty) Start() error {
+	t.conn.SetReadDeadline(time.Time{})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started {
+		return nil
+	}
+	t.started = true
+
+	// we echo, there are no go aheads, and tell us your window size
+	_, err := t.conn.Write([]byte{
+		telnetIAC, telnetWILL, telnetEcho,
+		telnetIAC, telnetWILL, telnetSGA,
+		telnetIAC, telnetDONT, telnetLinemode,
+		telnetIAC, telnetDO, telnetNAWS,
+	})
+	return err
+}
+
+func (t *telnetTty) Stop() error {
+	return nil
+}
+
+// Drain wakes up a blocked Read by making it time out.
+func (t *telnetTty) Drain() error {
+	return t.conn.SetReadDeadline(time.Now())
+}
+
+func (t *telnetTty) NotifyResize(cb func()) {
+	t.mu.Lock()
+	t.resize = cb
+	t.mu.Unlock()
+}
+
+func (t *telnetTty) WindowSize() (int, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width, t.height, nil
+}
+
+// Read reads from the connection, returning only the data bytes. It
+// blocks until there is at least one.
+func (t *telnetTty) Read(p []byte) (int, error) {
+	if len(t.unread) > 0 {
+		n := copy(p, t.unread)
+		t.unread = t.unread[n:]
+		return n, nil
+	}
+	buf := make([]byte, len(p))
+	for {
+		n, err := t.conn.Read(buf)
+		out := t.filter(buf[:n], p)
+		if out > 0 || err != nil {
+			return out, err
+		}
+	}
+}
+
+// filter copies the data bytes of in to out, acting on any telnet
+// commands along the way. Enter is sent by clients as CR LF or CR NUL,
+// and is passed on as a lone CR.
+func (t *telnetTty) filter(in, out []byte) int {
+	n := 0
+	for _, b := range in {
+		switch t.state {
+		case telnetData, telnetCR:
+			cr := t.state == telnetCR
+			t.state = telnetData
+			switch {
+			case b == telnetIAC:
+				t.state = telnetCommand
+			case cr && (b == '\n' || b == 0):
+			default:
+				out[n] = b
+				n++
+				if b == '\r' {
+					t.state = telnetCR
+				}
+			}
+
+		case telnetCommand:
+			switch b {
+			case telnetIAC:
+				out[n] = b
+				n++
+				t.state = telnetData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				t.state = telnetOption
+			case telnetSB:
+				t.sub = t.sub[:0]
+				t.state = telnetSub
+			default:
+				t.state = telnetData
+			}
+
+		case telnetOption:
+			// we only ask, so the answers need no reply
+			t.state = telnetData
+
+		case telnetSub:
+			if b == telnetIAC {
+				t.state = telnetSubIAC
+			} else {
+				t.sub = append(t.sub, b)
+			}
+
+		case telnetSubIAC:
+			switch b {
+			case telnetSE:
+				t.subnegotiation(t.sub)
+				t.state = telnetData
+			default:
+				// IAC IAC is a literal 255
+				t.sub = append(t.sub, b)
+				t.state = telnetSub
+			}
+		}
+	}
+	return n
+}
+
+func (t *telnetTty) subnegotiation(sub []byte) {
+	if len(sub) < 5 || sub[0] != telnetNAWS {
+		return
+	}
+
+	t.mu.Lock()
+	t.width = int(sub[1])<<8 | int(sub[2])
+	t.height = int(sub[3])<<8 | int(sub[4])
+	cb := t.resize
+	t.mu.Unlock()
+
+	if cb != nil {
+		cb()
+	}
+}
+
+// Write writes p as is; a 255 would have to be escaped, but UTF-8
+// never has one.
+func (t *telnetTty) Write(p []byte) (int, error) {
+	return t.conn.Write(p)
+}
+
+func (t *telnetTty) Close() error {
+	return t.conn.Close()
+}
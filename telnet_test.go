@@ -0,0 +1,113 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestTelnetFilter(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   [][]byte // read one after another
+		out  string
+	}{
+		{"data", [][]byte{[]byte("hello")}, "hello"},
+		{"enter as CR LF", [][]byte{[]byte("a\r\nb")}, "a\rb"},
+		{"enter as CR NUL", [][]byte{{'a', '\r', 0, 'b'}}, "a\rb"},
+		{"enter split between reads", [][]byte{[]byte("a\r"), []byte("\nb")}, "a\rb"},
+		{"lone CR", [][]byte{[]byte("a\rb")}, "a\rb"},
+		{"escaped 255", [][]byte{{'a', telnetIAC, telnetIAC, 'b'}}, "a\xffb"},
+		{"option answers", [][]byte{{telnetIAC, telnetDO, telnetEcho, 'a', telnetIAC, telnetWONT, telnetLinemode, 'b'}}, "ab"},
+		{"command split between reads", [][]byte{{'a', telnetIAC}, {telnetWILL}, {telnetNAWS, 'b'}}, "ab"},
+		{"other command", [][]byte{{'a', telnetIAC, 241, 'b'}}, "ab"},
+		{"subnegotiation", [][]byte{{'a', telnetIAC, telnetSB, telnetNAWS, 0, 100, 0, 30, telnetIAC, telnetSE, 'b'}}, "ab"},
+	} {
+		tty := newTelnetTty(nil)
+		var got []byte
+		for _, in := range tt.in {
+			out := make([]byte, len(in))
+			got = append(got, out[:tty.filter(in, out)]...)
+		}
+		if string(got) != tt.out {
+			t.Errorf("%s: filter = %q, want %q", tt.name, got, tt.out)
+		}
+	}
+}
+
+func TestTelnetWindowSize(t *testing.T) {
+	tty := newTelnetTty(nil)
+	resized := 0
+	tty.NotifyResize(func() { resized++ })
+
+	for _, tt := range []struct {
+		in            []byte
+		width, height int
+		resized       int
+	}{
+		// IAC SB NAWS 0 100 0 30 IAC SE
+		{[]byte{telnetIAC, telnetSB, telnetNAWS, 0, 100, 0, 30, telnetIAC, telnetSE}, 100, 30, 1},
+		// a 255 in the size is sent doubled
+		{[]byte{telnetIAC, telnetSB, telnetNAWS, 1, telnetIAC, telnetIAC, 0, 40, telnetIAC, telnetSE}, 511, 40, 2},
+		// too short, or some other option, is ignored
+		{[]byte{telnetIAC, telnetSB, telnetNAWS, 0, 90, telnetIAC, telnetSE}, 511, 40, 2},
+		{[]byte{telnetIAC, telnetSB, 24, 0, 90, 0, 20, telnetIAC, telnetSE}, 511, 40, 2},
+	} {
+		tty.filter(tt.in, make([]byte, len(tt.in)))
+		w, h, _ := tty.WindowSize()
+		if w != tt.width || h != tt.height || resized != tt.resized {
+			t.Errorf("after %v: size %dx%d, resized %d times, want %dx%d, %d times", tt.in, w, h, resized, tt.width, tt.height, tt.resized)
+		}
+	}
+}
+
+func TestAskName(t *testing.T) {
+	for _, tt := range []struct {
+		typed, name string
+		rest        string // read after the name
+	}{
+		{"Ada Lovelace\r\n", "Ada Lovelace", ""},
+		{"Ada\r\n  j\x1b[B", "Ada", "  j\x1b[B"},
+		{"Ada\rj", "Ada", "j"},
+		{"  Ada \r\x00", "Ada", ""},
+		{"\r\n", "", ""},
+		{"Adx\x7fa\r", "Ada", ""},
+		{"Zoé\x7fe\r", "Zoe", ""},
+		{string([]byte{telnetIAC, telnetWILL, telnetNAWS}) + "Ada\r\n", "Ada", ""},
+		{"abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz\r\n", "abcdefghijklmnopqrstuvwxyzabcdefghijklmn", ""},
+	} {
+		client, server := net.Pipe()
+		go func() {
+			io.ReadFull(client, make([]byte, len("What is your name? ")))
+			client.Write([]byte(tt.typed))
+		}()
+		tty := newTelnetTty(server)
+		name, err := askName(tty)
+		if err != nil || name != tt.name {
+			t.Errorf("askName(%q) = %q, %v, want %q", tt.typed, name, err, tt.name)
+		}
+		if rest := string(tty.unread); rest != tt.rest {
+			t.Errorf("askName(%q) left %q to read, want %q", tt.typed, rest, tt.rest)
+		}
+		if tt.rest != "" {
+			p := make([]byte, len(tt.rest))
+			if n, err := tty.Read(p); err != nil || string(p[:n]) != tt.rest {
+				t.Errorf("Read after askName(%q) = %q, %v, want %q", tt.typed, p[:n], err, tt.rest)
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+
+	// hanging up is an error
+	client, server := net.Pipe()
+	go func() {
+		io.ReadFull(client, make([]byte, len("What is your name? ")))
+		client.Close()
+	}()
+	if _, err := askName(newTelnetTty(server)); err == nil {
+		t.Error("askName after hanging up: no error")
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !(js && wasm)
+
 package main
 
 import (
@@ -13,17 +15,27 @@ type TermWindow struct {
 }
 
 func NewTermWindow(width, height int) *TermWindow {
-	defStyle := tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorGreen)
-
 	s, err := tcell.NewScreen()
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
-	if err := s.Init(); err != nil {
+	w, err := NewTermWindowFromScreen(s, width, height)
+	if err != nil {
 		log.Fatalf("%+v", err)
 	}
+	return w
+}
 
-	s.SetSize(79, 20) // try resizing to optimal width
+// NewTermWindowFromScreen is NewTermWindow for a screen that has been
+// created but not initialised yet, such as one for a remote terminal.
+func NewTermWindowFromScreen(s tcell.Screen, width, height int) (*TermWindow, error) {
+	defStyle := tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorGreen)
+
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+
+	s.SetSize(width, height) // try resizing to optimal width
 	s.EnableMouse()
 	s.SetStyle(defStyle)
 	s.Clear()
@@ -31,7 +43,7 @@ func NewTermWindow(width, height int) *TermWindow {
 
 	DrawOverlay(&w)
 
-	return &w
+	return &w, nil
 }
 
 func (w *TermWindow) ChannelEvents() (evChan chan event, quit chan struct{}) {
@@ -45,15 +57,20 @@ func (w *TermWindow) pipeline(evChan chan<- event, quit chan struct{}) {
 	ichan := make(chan tcell.Event)
 	iquit := make(chan struct{})
 	go w.Screen.ChannelEvents(ichan, iquit)
+	defer close(iquit)
 	for {
 		select {
 		case <-quit:
-			iquit <- struct{}{}
-			close(evChan)
-			close(quit)
 			return
-		case e := <-ichan:
-			evChan <- tcellToEvent(e)
+		case e, ok := <-ichan:
+			if !ok { // screen has been finalised
+				return
+			}
+			select {
+			case evChan <- tcellToEvent(e):
+			case <-quit:
+				return
+			}
 		}
 	}
 }
@@ -76,95 +93,67 @@ func tcellToEvent(e tcell.Event) event {
 			return &specialEvent{backspace}
 		case tcell.KeyEnter:
 			return &specialEvent{enter}
+		case tcell.KeyDelete:
+			return &specialEvent{del}
+		case tcell.KeyHome:
+			return &specialEvent{home}
+		case tcell.KeyEnd:
+			return &specialEvent{end}
+		case tcell.KeyCtrlW:
+			return &specialEvent{deleteWord}
 		case tcell.KeyCtrlC:
 			return &specialEvent{quit}
 		case tcell.KeyESC:
 			return &specialEvent{reset}
 		}
+	case *tcell.EventResize:
+		width, height := ev.Size()
+		return &resizeEvent{width, height}
 	case *tcell.EventMouse:
 		x, y := ev.Position()
-		return &mouseEvent{x, y}
+		return &mouseEvent{x, y, ev.Buttons()&tcell.Button1 != 0}
+	case *tcell.EventError:
+		// the terminal has gone away
+		return &specialEvent{quit}
 	}
 	return &keyEvent{}
 }
 
-func (w *TermWindow) GetContent(x, y int) (rune, style) {
-	a, _, b, _ := w.Screen.GetContent(x, y)
-	return a, tcellToStyle(b)
+func (w *TermWindow) GetContent(x, y int) (rune, []rune, Style) {
+	mainc, combc, s, _ := w.Screen.GetContent(x, y)
+	return mainc, combc, tcellToStyle(s)
 }
 
-func (w *TermWindow) ResolutionCheck(e *tcell.EventResize, old *VirtualRegion) *VirtualRegion {
-	width, height := e.Size()
+// ResolutionCheck is called when the terminal becomes width by height.
+// If that is smaller than the window was made, what is drawn is saved,
+// unless old already was, and a warning shown instead; the saved
+// content is returned. Otherwise the drawing rect fills the terminal
+// and nil is returned. If old was given or the drawing rect changed,
+// the screen is left clear for the scene to be reflowed onto; old
+// isn't put back, as the terminal cut it down before we were told.
+func (w *TermWindow) ResolutionCheck(width, height int, old *VirtualRegion) *VirtualRegion {
 	if width < w.width || height < w.height {
 		if old != nil {
 			return old
 		}
 		vr := CopyContent(w.DrawableRect, w)
 		w.Screen.Clear()
-		w.displayResolutionWarning(width, height)
+		DrawResolutionWarning(w.width, w.height, w)
 		return &vr
-	} else if old != nil {
-		w.Screen.Clear()
-		DrawOverlay(w)
-		old.PasteContent(w.DrawableRect.x, w.DrawableRect.y, w)
 	}
-	return nil
-}
-
-func (w *TermWindow) displayResolutionWarning(width, height int) {
-	DrawText("79x20 is min size.\nPlease expand your terminal.", &Rect{0, 0, width, height}, normal, w)
-}
-
-func (w *TermWindow) SetContent(x, y int, r rune, s style) {
-	var st tcell.Style
-	switch s {
-	case normal:
-		st = tcell.StyleDefault
-	case option:
-		st = tcell.StyleDefault.Foreground(tcell.ColorYellow)
-
-	case popup:
-		st = tcell.StyleDefault.Foreground(tcell.ColorTurquoise)
-
-	case popupBox:
-		st = tcell.StyleDefault.Foreground(tcell.ColorDarkTurquoise)
 
-	case t1ln:
-		st = tcell.StyleDefault.Foreground(tcell.ColorGoldenrod)
-
-	case t1en:
-		st = tcell.StyleDefault.Foreground(tcell.ColorPink)
-
-	case t1ne:
-		st = tcell.StyleDefault.Foreground(tcell.ColorRed)
-
-	case t2ne:
-		st = tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	dr := Rect{0, 1, width, height - 1}
+	if old == nil && dr == *w.DrawableRect {
+		return nil
 	}
+	w.Screen.Clear()
+	DrawOverlay(w)
+	*w.DrawableRect = dr
+	return nil
+}
 
-	w.Screen.SetContent(x, y, r, nil, st)
-}
-
-func tcellToStyle(s tcell.Style) style {
-	switch s {
-	case tcell.StyleDefault:
-		return normal
-	case tcell.StyleDefault.Foreground(tcell.ColorYellow):
-		return option
-	case tcell.StyleDefault.Foreground(tcell.ColorTurquoise):
-		return popup
-	case tcell.StyleDefault.Foreground(tcell.ColorDarkTurquoise):
-		return popupBox
-	case tcell.StyleDefault.Foreground(tcell.ColorGoldenrod):
-		return t1ln
-	case tcell.StyleDefault.Foreground(tcell.ColorPink):
-		return t1en
-	case tcell.StyleDefault.Foreground(tcell.ColorRed):
-		return t1ne
-	case tcell.StyleDefault.Foreground(tcell.ColorBlue):
-		return t2ne
-	}
-	return normal
+func (w *TermWindow) SetContent(x, y int, mainc rune, combc []rune, s Style) {
+	w.Screen.SetContent(x, y, mainc, combc, s.tcell())
 }
 
 func (w *TermWindow) HideCursor() {
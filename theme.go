@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Ahoys123/tcell"
+)
+
+// Theme is the Style each style is drawn with, in the terminal and in
+// the browser alike.
+type Theme struct {
+	styles map[style]Style
+}
+
+// Style is how s is drawn, or how normal is if the theme doesn't say.
+func (t *Theme) Style(s style) Style {
+	if ts, ok := t.styles[s]; ok {
+		return ts
+	}
+	return t.styles[normal]
+}
+
+// styleOf is the style drawn as st, if there is one. Where styles are
+// drawn alike the first is taken, as it looks the same.
+func (t *Theme) styleOf(st Style) (style, bool) {
+	for s := normal; s <= mistake; s++ {
+		if t.Style(s) == st {
+			return s, true
+		}
+	}
+	return normal, false
+}
+
+// theme is the Theme every window draws with; see UseTheme.
+var theme = themes["default"]
+
+// UseTheme makes t the theme windows draw with from now on.
+func UseTheme(t *Theme) {
+	theme = t
+}
+
+// themes are the built in themes, by name. Red and blue are all that
+// tell Type 1 code from Type 2 in the default theme, so the others also
+// set them apart with bold and italics. Words to hover over are
+// underlined on top of their style.
+var themes = map[string]*Theme{
+	"default": {map[style]Style{
+		normal:   {},
+		option:   {Fg: tcell.ColorYellow},
+		popup:    {Fg: tcell.ColorTurquoise},
+		popupBox: {Fg: tcell.ColorDarkTurquoise},
+		t1ln:     {Fg: tcell.ColorGoldenrod},
+		t1en:     {Fg: tcell.ColorPink},
+		t1ne:     {Fg: tcell.ColorRed},
+		t2ne:     {Fg: tcell.ColorBlue},
+		mistake:  Style{Fg: tcell.ColorOrangeRed}.Underline(),
+	}},
+
+	// high-contrast is bright colours on black
+	"high-contrast": {map[style]Style{
+		normal:   {tcell.ColorWhite, tcell.ColorBlack, 0},
+		option:   Style{tcell.ColorYellow, tcell.ColorBlack, 0}.Bold(),
+		popup:    {tcell.ColorAqua, tcell.ColorBlack, 0},
+		popupBox: {tcell.ColorWhite, tcell.ColorNavy, 0},
+		t1ln:     {tcell.ColorYellow, tcell.ColorBlack, 0},
+		t1en:     {tcell.ColorFuchsia, tcell.ColorBlack, 0},
+		t1ne:     Style{tcell.ColorRed, tcell.ColorBlack, 0}.Bold(),
+		t2ne:     Style{tcell.ColorAqua, tcell.ColorBlack, 0}.Italic(),
+		mistake:  {tcell.ColorBlack, tcell.ColorYellow, 0},
+	}},
+
+	// colorblind uses the Okabe-Ito colours, which stay apart with
+	// every common kind of colour blindness
+	"colorblind": {map[style]Style{
+		normal:   {},
+		option:   Style{Fg: tcell.GetColor("#f0e442")}.Bold(),
+		popup:    {Fg: tcell.GetColor("#009e73")},
+		popupBox: {Fg: tcell.GetColor("#0072b2")},
+		t1ln:     {Fg: tcell.GetColor("#f0e442")},
+		t1en:     {Fg: tcell.GetColor("#cc79a7")},
+		t1ne:     Style{Fg: tcell.GetColor("#e69f00")}.Bold(),
+		t2ne:     Style{Fg: tcell.GetColor("#56b4e9")}.Italic(),
+		mistake:  Style{Fg: tcell.GetColor("#d55e00")}.Reverse(),
+	}},
+}
+
+// LoadTheme loads the built in theme called name, or if there isn't one,
+// the theme file at name. A theme file is JSON mapping style names to
+// how they are drawn:
+//
+//	{
+//		"option": {"fg": "yellow", "bold": true},
+//		"t1ne": {"fg": "#e69f00", "bg": "black", "underline": true}
+//	}
+//
+// Colours are W3C names or #rrggbb, and "default" for the terminal's
+// own. The attributes are bold, dim, italic, underline, reverse, blink
+// and strikethrough. Styles the file leaves out are drawn as in the
+// default theme.
+func LoadTheme(name string) (*Theme, error) {
+	if t, ok := themes[name]; ok {
+		return t, nil
+	}
+
+	b, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTheme(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return t, nil
+}
+
+// styleSpec is how a theme file says to draw a style.
+type styleSpec struct {
+	Fg            string `json:"fg"`
+	Bg            string `json:"bg"`
+	Bold          bool   `json:"bold"`
+	Dim           bool   `json:"dim"`
+	Italic        bool   `json:"italic"`
+	Underline     bool   `json:"underline"`
+	Reverse       bool   `json:"reverse"`
+	Blink         bool   `json:"blink"`
+	StrikeThrough bool   `json:"strikethrough"`
+}
+
+func parseTheme(b []byte) (*Theme, error) {
+	var specs map[string]styleSpec
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return nil, err
+	}
+
+	t := &Theme{map[style]Style{}}
+	for s, ts := range themes["default"].styles {
+		t.styles[s] = ts
+	}
+	for name, spec := range specs {
+		s, ok := parseStyle(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown style %q", name)
+		}
+		fg, err := parseColor(spec.Fg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		bg, err := parseColor(spec.Bg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		st := Style{fg, bg, tcell.AttrNone}
+		for attr, on := range map[tcell.AttrMask]bool{
+			tcell.AttrBold: spec.Bold, tcell.AttrDim: spec.Dim,
+			tcell.AttrItalic: spec.Italic, tcell.AttrUnderline: spec.Underline,
+			tcell.AttrReverse: spec.Reverse, tcell.AttrBlink: spec.Blink,
+			tcell.AttrStrikeThrough: spec.StrikeThrough,
+		} {
+			if on {
+				st = st.with(attr)
+			}
+		}
+		t.styles[s] = st
+	}
+	return t, nil
+}
+
+// parseStyle is the style called name, as style.String has it.
+func parseStyle(name string) (style, bool) {
+	for s := normal; s <= mistake; s++ {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return normal, false
+}
+
+// parseColor is the colour called name in a theme file.
+func parseColor(name string) (tcell.Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "default" {
+		return tcell.ColorDefault, nil
+	}
+	c := tcell.GetColor(name)
+	if c == tcell.ColorDefault {
+		return c, fmt.Errorf("unknown colour %q", name)
+	}
+	return c, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ahoys123/tcell"
+)
+
+func TestParseTheme(t *testing.T) {
+	th, err := parseTheme([]byte(`{
+		"option": {"fg": "Yellow", "bold": true},
+		"t1ne": {"fg": "#e69f00", "bg": "black", "underline": true, "italic": true},
+		"mistake": {"reverse": true, "strikethrough": true, "dim": true, "blink": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s, want := range map[style]Style{
+		option:  {tcell.ColorYellow, tcell.ColorDefault, tcell.AttrBold},
+		t1ne:    {tcell.NewHexColor(0xe69f00), tcell.ColorBlack, tcell.AttrUnderline | tcell.AttrItalic},
+		mistake: {tcell.ColorDefault, tcell.ColorDefault, tcell.AttrReverse | tcell.AttrStrikeThrough | tcell.AttrDim | tcell.AttrBlink},
+		// left out, so as in the default theme
+		t2ne:   themes["default"].Style(t2ne),
+		normal: {},
+	} {
+		if got := th.Style(s); got != want {
+			t.Errorf("%v = %+v, want %+v", s, got, want)
+		}
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		json, err string
+	}{
+		{`{"t3ne": {"fg": "red"}}`, `unknown style "t3ne"`},
+		{`{"option": {"fg": "reddish"}}`, `option: unknown colour "reddish"`},
+		{`{"option": {"bg": "#12345"}}`, `option: unknown colour "#12345"`},
+		{`{"option": "red"}`, `cannot unmarshal`},
+		{`{`, `unexpected end`},
+	} {
+		_, err := parseTheme([]byte(tt.json))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseTheme(%s) = %v, want %s", tt.json, err, tt.err)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for name, want := range map[string]tcell.Color{
+		"":          tcell.ColorDefault,
+		"default":   tcell.ColorDefault,
+		"red":       tcell.ColorRed,
+		" Navy ":    tcell.ColorNavy,
+		"#56b4e9":   tcell.NewHexColor(0x56b4e9),
+		"turquoise": tcell.ColorTurquoise,
+	} {
+		if got, err := parseColor(name); got != want || err != nil {
+			t.Errorf("parseColor(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	for name := range themes {
+		if th, err := LoadTheme(name); err != nil || th != themes[name] {
+			t.Errorf("LoadTheme(%q) = %p, %v, want the built in theme", name, th, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "mine.json")
+	if err := os.WriteFile(path, []byte(`{"option": {"fg": "green"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	th, err := LoadTheme(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := th.Style(option); got != (Style{Fg: tcell.ColorGreen}) {
+		t.Errorf("option = %+v", got)
+	}
+
+	os.WriteFile(path, []byte(`{"option": {"fg": "greenish"}}`), 0o644)
+	if _, err := LoadTheme(path); err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("LoadTheme of a bad theme = %v, want an error naming the file", err)
+	}
+	if _, err := LoadTheme(filepath.Join(t.TempDir(), "none.json")); err == nil {
+		t.Error("LoadTheme of a missing file: no error")
+	}
+}
+
+func TestThemeStyles(t *testing.T) {
+	// every style is drawn apart from the others, so that it can be
+	// told which one a cell was drawn in
+	for name, th := range themes {
+		for s := normal; s <= mistake; s++ {
+			if got, ok := th.styleOf(th.Style(s)); !ok || got != s {
+				t.Errorf("%s: styleOf(%v) = %v, %v", name, s, got, ok)
+			}
+		}
+		if _, ok := th.styleOf(Style{Fg: tcell.ColorLime, Attrs: tcell.AttrBlink}); ok {
+			t.Errorf("%s: styleOf a style it doesn't have is found", name)
+		}
+	}
+}
@@ -1,39 +1,12 @@
 package main
 
 import (
+	"math/rand"
 	"strings"
+	"sync"
+	"unicode"
 )
 
-/*
-type Translator map[string]string
-
-type navajo struct {
-	word        string
-	translation string
-}
-
-func (n *navajo) getWord() string {
-	return n.word
-}
-
-func (n *navajo) getTranslation() string {
-	return n.translation
-}
-
-func toCode(toTranslate string) string {
-	tr := ""
-	for i, char := range toTranslate {
-		if letters[char] != nil {
-			tr += strings.Title(letters[char].getTranslation())
-			if i != len(toTranslate)-1 {
-				tr += "-"
-			}
-		}
-	}
-	return tr
-}
-*/
-
 type translation struct {
 	trans string
 	s     style
@@ -47,98 +20,17 @@ func (tr translation) getColor() style {
 	return tr.s
 }
 
-var master ReplaceMap = map[string]translation{
-	"a": {"wóláchííʼ\nant", t1ln},
-	"b": {"shash\nbear", t1ln},
-	"c": {"mósí\ncat", t1ln},
-	"d": {"bįįh\ndeer", t1ln},
-	"e": {"dzééh\nelk", t1ln},
-	"f": {"mąʼii\nfox", t1ln},
-	"g": {"tłʼízí\ngoat", t1ln},
-	"h": {"chʼah\nhat", t1ln},
-	"i": {"tin\nice", t1ln},
-	"j": {"téliichoʼí\njackass", t1ln},
-	"k": {"tłʼízí yázhí\nkid", t1ln},
-	"l": {"ajáád\nleg", t1ln},
-	"m": {"naʼatsʼǫǫsí\nmouse", t1ln},
-	"n": {"tsah\nneedle", t1ln},
-	"o": {"tłʼohchin\nonion", t1ln},
-	"p": {"bisóodi\npig", t1ln},
-	"q": {"kʼaaʼ yeiłtįįh\nquiver", t1ln},
-	"r": {"gah\nrabbit", t1ln},
-	"s": {"dibé\nsheep", t1ln},
-	"t": {"dééh\ntea", t1ln},
-	"u": {"shidáʼí\nuncle", t1ln},
-	"v": {"akʼehdidlíní\nvictor", t1ln},
-	"w": {"dlǫ́ʼii\nweasel", t1ln},
-	"x": {"ałnáʼázdzoh\ncross", t1ln},
-	"y": {"tsáʼásziʼ\nyucca", t1ln},
-	"z": {"béésh dootłʼizh\nzinc", t1ln},
-
-	"ant":     {"wóláchííʼ", t1en},
-	"bear":    {"shash", t1en},
-	"cat":     {"mósí", t1en},
-	"deer":    {"bįįh", t1en},
-	"elk":     {"dzééh", t1en},
-	"fox":     {"mąʼii", t1en},
-	"goat":    {"tłʼízí", t1en},
-	"hat":     {"chʼah", t1en},
-	"ice":     {"tin", t1en},
-	"jackass": {"téliichoʼí", t1en},
-	"kid":     {"tłʼízí yázhí", t1en},
-	"leg":     {"ajáád", t1en},
-	"mouse":   {"naʼatsʼǫǫsí", t1en},
-	"needle":  {"tsah", t1en},
-	"onion":   {"tłʼohchin", t1en},
-	"pig":     {"bisóodi", t1en},
-	"quiver":  {"kʼaaʼ yeiłtįįh", t1en},
-	"rabbit":  {"gah", t1en},
-	"sheep":   {"dibé", t1en},
-	"tea":     {"dééh", t1en},
-	"uncle":   {"shidáʼí", t1en},
-	"victor":  {"akʼehdidlíní", t1en},
-	"weasel":  {"dlǫ́ʼii", t1en},
-	"cross":   {"ałnáʼázdzoh", t1en},
-	"yucca":   {"tsáʼásziʼ", t1en},
-	"zinc":    {"béésh dootłʼizh", t1en},
-
-	"wóláchííʼ":       {"ant", t1ne},
-	"shash":           {"bear", t1ne},
-	"mósí":            {"cat", t1ne},
-	"bįįh":            {"deer", t1ne},
-	"dzééh":           {"elk", t1ne},
-	"mąʼii":           {"fox", t1ne},
-	"tłʼízí":          {"goat", t1ne},
-	"chʼah":           {"hat", t1ne},
-	"tin":             {"ice", t1ne},
-	"téliichoʼí":      {"jackass", t1ne},
-	"tłʼízí yázhí":    {"kid", t1ne},
-	"ajáád":           {"leg", t1ne},
-	"naʼatsʼǫǫsí":     {"mouse", t1ne},
-	"tsah":            {"needle", t1ne},
-	"tłʼohchin":       {"onion", t1ne},
-	"bisóodi":         {"pig", t1ne},
-	"kʼaaʼ yeiłtįįh":  {"quiver", t1ne},
-	"gah":             {"rabbit", t1ne},
-	"dibé":            {"sheep", t1ne},
-	"dééh":            {"tea", t1ne},
-	"shidáʼí":         {"uncle", t1ne},
-	"akʼehdidlíní":    {"victor", t1ne},
-	"dlǫ́ʼii":         {"weasel", t1ne},
-	"ałnáʼázdzoh":     {"cross", t1ne},
-	"tsáʼásziʼ":       {"yucca", t1ne},
-	"béésh dootłʼizh": {"zinc", t1ne},
-
-	"yókeed":      {"ask", t2ne},
-	"naakáí":      {"company\n\"Mexican\"", t2ne},
-	"hohkááh":     {"come", t2ne},
-	"tó nilį́į́h": {"creek", t2ne},
-	"iron fish":   {"béésh łóóʼ", t2ne},
-	"whale":       {"łóóʼtsoh", t2ne},
-	"tsídii":      {"bird", t2ne},
-	"mobba yéhé":  {"it transports", t2ne},
+// type1Word is a Navajo word a letter can be spelt with, and the
+// English word it means.
+type type1Word struct {
+	navajo, english string
 }
 
+// master is the ReplaceMap generated from the loaded dictionary, and
+// defaultTranslator the Translator for it. See UseDictionary.
+var master = ReplaceMap{}
+var defaultTranslator = NewTranslator(nil)
+
 // Replacer is basically a read only map.
 type Replacer interface {
 	getText(string) string
@@ -155,56 +47,328 @@ func (rm ReplaceMap) getColor(text string) style {
 	return rm[strings.ToLower(text)].getColor()
 }
 
+// HoverReplace lays text out in rect, returning the calls to draw it
+// and popups over the parts in {braces}, which show their tooltip or
+// what rplcr has for them. See parseMarkup for the rest of the markup.
 func HoverReplace(textS string, rplcr Replacer, rect *Rect, w Window) (pus []*PopUp, dcs []drawCall) {
-	text := []rune(textS)
-	start := 0
-	highlight := false
-	offset := 0 // where to draw text; - is before "true" pos, + is after
-	nextOffset := -1
-	var r rune
-	for i := 0; i <= len(text); i++ {
-		if i < len(text) {
-			r = text[i]
-		}
-		if (i == len(text) || (r == '{' || r == '}')) && (start-i <= 1) {
-			sub := string(text[start:i]) // substring
-
-			dcs = append(dcs, drawCall{
-				sub,
-				rect,
-				start + offset,
-				rplcr.getColor(strings.ToLower(sub)),
-			})
-
-			if highlight {
-				for _, v := range GetDrawingRect(sub, rect, start+offset) {
-					dictVal := rplcr.getText(strings.ToLower(sub))
-					width, height := GetDimensions(dictVal)
-
-					pus = append(pus, NewPopUp(
-						dictVal,
-						width,
-						height,
-						v,
-						w,
-					))
-				}
+	mt := parseMarkup(textS, rplcr)
+	run := layoutGlyphs(mt.gs, rect.w, 0)
+	return mt.popups(run, rect, w), []drawCall{{mt.plain(), rect, run, mt.styles}}
+}
+
+// CodeType is the kind of code a CodeWord belongs to.
+type CodeType uint8
+
+const (
+	// Untranslated words have no code and are passed through as is.
+	Untranslated CodeType = iota
+	// Type1 words spell out a single English letter.
+	Type1
+	// Type2 words stand for a whole English term.
+	Type2
+)
+
+// CodeWord is one Navajo code word and what it stands for; a letter
+// for Type 1 words and a term for Type 2 words. Gloss is what the word
+// means on its own, e.g. "ant" for the letter a or "iron fish" for
+// submarine, if the dictionary says.
+type CodeWord struct {
+	Navajo  string
+	English string
+	Gloss   string
+	Type    CodeType
+}
+
+// Ambiguity is a point in a decoded message that could be read more
+// than one way. Pos is the index of the first Navajo word involved,
+// not counting "/" separators or lone punctuation. An unknown word is
+// reported with no Options.
+type Ambiguity struct {
+	Pos     int
+	Navajo  string
+	Options []CodeWord
+}
+
+// Decoded is the result of decoding a message.
+type Decoded struct {
+	Text        string
+	Words       []CodeWord
+	Ambiguities []Ambiguity
+}
+
+// Translator encodes English into code talker Navajo and back. Known
+// military terms use Type 2 code, everything else is spelt out letter
+// by letter in Type 1 code. Letters with several Type 1 words rotate
+// through them, or pick one at random after Randomise.
+type Translator struct {
+	letters map[rune][]type1Word
+	terms   map[string]CodeWord
+	reverse map[string][]CodeWord
+
+	mu   sync.Mutex // guards next and rnd, Translators are shared
+	next map[rune]int
+	rnd  *rand.Rand
+
+	longestTerm   int // in English words
+	longestNavajo int // in Navajo words
+}
+
+// NewTranslator creates a Translator from dictionary entries. Earlier
+// entries win when a Navajo word could be read more than one way.
+func NewTranslator(entries []DictEntry) *Translator {
+	t := &Translator{
+		letters: map[rune][]type1Word{},
+		terms:   map[string]CodeWord{},
+		reverse: map[string][]CodeWord{},
+		next:    map[rune]int{},
+	}
+
+	for _, de := range entries {
+		switch de.Type {
+		case Type1:
+			l := []rune(strings.ToLower(de.English))[0]
+			t.letters[l] = append(t.letters[l], type1Word{de.Navajo, de.Literal})
+			t.addReverse(CodeWord{de.Navajo, string(l), de.Literal, Type1})
+		case Type2:
+			en := strings.Join(strings.Fields(strings.ToLower(de.English)), " ")
+			cw := CodeWord{de.Navajo, en, de.Literal, Type2}
+			if _, ok := t.terms[en]; !ok {
+				t.terms[en] = cw
+			}
+			t.addReverse(cw)
+			if n := len(strings.Fields(en)); n > t.longestTerm {
+				t.longestTerm = n
+			}
+		}
+	}
+
+	return t
+}
+
+func (t *Translator) addReverse(cw CodeWord) {
+	key := strings.Join(strings.Fields(strings.ToLower(cw.Navajo)), " ")
+	t.reverse[key] = append(t.reverse[key], cw)
+	if n := len(strings.Fields(key)); n > t.longestNavajo {
+		t.longestNavajo = n
+	}
+}
+
+// Randomise makes the Translator pick a random Type 1 word for each
+// letter from r instead of rotating through them.
+func (t *Translator) Randomise(r *rand.Rand) {
+	t.mu.Lock()
+	t.rnd = r
+	t.mu.Unlock()
+}
+
+// letter picks the Type 1 word to spell l with.
+func (t *Translator) letter(l rune) (type1Word, bool) {
+	words := t.letters[l]
+	if len(words) == 0 {
+		return type1Word{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rnd != nil {
+		return words[t.rnd.Intn(len(words))], true
+	}
+	i := t.next[l] % len(words)
+	t.next[l] = i + 1
+	return words[i], true
+}
+
+// Encode translates English text into code words, one slice per
+// English word or Type 2 term.
+func (t *Translator) Encode(english string) (words [][]CodeWord) {
+	fields := splitWords(english)
+
+	for i := 0; i < len(fields); {
+		// longest Type 2 term first
+		n := t.longestTerm
+		if n > len(fields)-i {
+			n = len(fields) - i
+		}
+		for ; n > 0; n-- {
+			term := strings.Join(fields[i:i+n], " ")
+			if cw, ok := t.terms[term]; ok {
+				words = append(words, []CodeWord{cw})
+				break
 			}
+		}
+		if n > 0 {
+			i += n
+			continue
+		}
 
-			offset += nextOffset
-			nextOffset = -1
-			start = i + 1
-			highlight = !highlight
-		} else if r == '\n' {
-			x := ((i + offset + 2 + nextOffset) % rect.w)
-			if x != 0 {
-				nextOffset += rect.w - x
+		// spell it out
+		var spelt []CodeWord
+		for _, r := range fields[i] {
+			if tw, ok := t.letter(r); ok {
+				spelt = append(spelt, CodeWord{tw.navajo, string(r), tw.english, Type1})
+			} else {
+				spelt = append(spelt, CodeWord{string(r), string(r), "", Untranslated})
 			}
-		} else if r == '\t' {
-			offset -= 1
-			nextOffset += 4
 		}
+		words = append(words, spelt)
+		i++
+	}
+
+	return words
+}
+
+// Spellings are the ways cw can be written in Navajo: any of its
+// letter's Type 1 words if it is one, or else just its own.
+func (t *Translator) Spellings(cw CodeWord) []string {
+	if cw.Type != Type1 {
+		return []string{cw.Navajo}
+	}
+	var sp []string
+	for _, tw := range t.letters[[]rune(cw.English)[0]] {
+		sp = append(sp, tw.navajo)
+	}
+	if len(sp) == 0 {
+		return []string{cw.Navajo}
+	}
+	return sp
+}
+
+// EncodeString translates English text into a line of Navajo code
+// words. Words are separated by " / " so the message can be decoded
+// again without guessing where Type 1 spellings end.
+func (t *Translator) EncodeString(english string) string {
+	var parts []string
+	for _, word := range t.Encode(english) {
+		var nv []string
+		for _, cw := range word {
+			nv = append(nv, cw.Navajo)
+		}
+		parts = append(parts, strings.Join(nv, " "))
 	}
+	return strings.Join(parts, " / ")
+}
+
+// Decode translates Navajo code words back into English. Runs of Type
+// 1 words are joined into a single English word until a Type 2 word or
+// a "/" separator. Words that are unknown, or that could be read more
+// than one way, are reported in Ambiguities; the first reading is used.
+// Apostrophes typed for glottal stops are read as ʼ.
+func (t *Translator) Decode(navajo string) *Decoded {
+	d := &Decoded{}
+	fields := strings.Fields(strings.ToLower(normGlottal(navajo)))
+
+	var out []string
+	spelling := false
+	pos := 0 // Navajo words decoded so far
+	for i := 0; i < len(fields); {
+		if fields[i] == "/" {
+			spelling = false
+			i++
+			continue
+		}
+
+		word := strings.TrimFunc(fields[i], unicode.IsPunct)
+		if word == "" {
+			spelling = false
+			i++
+			continue
+		}
+
+		n, opts := t.longestMatch(fields[i:])
+		if n == 0 {
+			d.Ambiguities = append(d.Ambiguities, Ambiguity{pos, word, nil})
+			d.Words = append(d.Words, CodeWord{word, word, "", Untranslated})
+			out = append(out, word)
+			spelling = false
+			i++
+			pos++
+			continue
+		}
+
+		span := opts[0].Navajo
+		if len(opts) > 1 || (n > 1 && t.segments(strings.Fields(strings.ToLower(span)))) {
+			d.Ambiguities = append(d.Ambiguities, Ambiguity{pos, span, opts})
+		}
+
+		cw := opts[0]
+		d.Words = append(d.Words, cw)
+		if cw.Type == Type1 && spelling {
+			out[len(out)-1] += cw.English
+		} else {
+			out = append(out, cw.English)
+		}
+
+		// trailing punctuation ends a spelt out word, like "/" does
+		last := fields[i+n-1]
+		spelling = cw.Type == Type1 && strings.TrimRightFunc(last, unicode.IsPunct) == last
+		i += n
+		pos += n
+	}
+
+	d.Text = strings.Join(out, " ")
+	return d
+}
+
+// longestMatch finds the longest run of words at the start of fields
+// that is a known code word, and every reading of it.
+func (t *Translator) longestMatch(fields []string) (int, []CodeWord) {
+	n := t.longestNavajo
+	if n > len(fields) {
+		n = len(fields)
+	}
+	for ; n > 0; n-- {
+		words := make([]string, n)
+		for i, f := range fields[:n] {
+			words[i] = strings.TrimFunc(f, unicode.IsPunct)
+		}
+		if opts, ok := t.reverse[strings.Join(words, " ")]; ok {
+			return n, opts
+		}
+	}
+	return 0, nil
+}
+
+// segments reports whether fields can also be read as a sequence of
+// shorter code words.
+func (t *Translator) segments(fields []string) bool {
+	for n := 1; n < len(fields); n++ {
+		if _, ok := t.reverse[strings.Join(fields[:n], " ")]; !ok {
+			continue
+		}
+		rest := fields[n:]
+		if _, ok := t.reverse[strings.Join(rest, " ")]; ok || t.segments(rest) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceMap builds a ReplaceMap from Navajo code words to their
+// English meaning, for use with HoverText.
+func (t *Translator) ReplaceMap() ReplaceMap {
+	rm := ReplaceMap{}
+	for nv, opts := range t.reverse {
+		var meanings []string
+		for _, cw := range opts {
+			if cw.Type == Type1 {
+				meanings = append(meanings, cw.Gloss)
+			} else {
+				meanings = append(meanings, cw.English)
+			}
+		}
+		s := t1ne
+		if opts[0].Type == Type2 {
+			s = t2ne
+		}
+		rm[nv] = translation{strings.Join(meanings, "\n"), s}
+	}
+	return rm
+}
 
-	return pus, dcs
+// splitWords lowercases text and splits it into words, dropping
+// punctuation.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && r != '\''
+	})
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testDict is a small dictionary with a multi-word Type 1 word, a
+// Type 1 word that starts another, and Type 2 terms of one, two and
+// three words.
+const testDict = "type\tenglish\tnavajo\tliteral\n" +
+	"1\ta\twóláchííʼ\tant\n" +
+	"1\ta\tbe-la-sana\tapple\n" +
+	"1\tb\tshash\tbear\n" +
+	"1\tc\tmósí\tcat\n" +
+	"1\tg\ttłʼízí\tgoat\n" +
+	"1\tk\ttłʼízí yázhí\tkid\n" +
+	"1\tt\tthan-zie\tturkey\n" +
+	"1\tz\tbesh-do-tliz\tzinc\n" +
+	"2\tsubmarine\tbéésh łóóʼ\tiron fish\n" +
+	"2\tcompany\tnaakáí\tMexican\n" +
+	"2\tfire\tbeeʼeldǫǫh\n" +
+	"2\taircraft carrier\ttsídii mobba yéhé\tbird carrier\n" +
+	"2\tyázhí\tyázhí\n"
+
+func testTranslator(t *testing.T) *Translator {
+	t.Helper()
+	d, err := ReadDictionary(strings.NewReader(testDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.Translator()
+}
+
+func TestEncodeString(t *testing.T) {
+	for _, tt := range []struct {
+		english, navajo string
+	}{
+		{"cab", "mósí wóláchííʼ shash"},
+		{"Submarine!", "béésh łóóʼ"},
+		// letters the dictionary doesn't have are passed through
+		{"the aircraft carrier", "than-zie h e / tsídii mobba yéhé"},
+		{"company b", "naakáí / shash"},
+		{"fire 2", "beeʼeldǫǫh / 2"},
+		{"", ""},
+	} {
+		if got := testTranslator(t).EncodeString(tt.english); got != tt.navajo {
+			t.Errorf("EncodeString(%q) = %q, want %q", tt.english, got, tt.navajo)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tr := testTranslator(t)
+	got := tr.Encode("cab submarine")
+	want := [][]CodeWord{
+		{{"mósí", "c", "cat", Type1}, {"wóláchííʼ", "a", "ant", Type1}, {"shash", "b", "bear", Type1}},
+		{{"béésh łóóʼ", "submarine", "iron fish", Type2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range []struct {
+		navajo, english string
+		ambiguous       []Ambiguity // Options left out, only their count checked
+		options         []int
+	}{
+		{"mósí wóláchííʼ shash", "cab", nil, nil},
+		{"Mósí Wóláchííʼ Shash.", "cab", nil, nil},
+		{"mósí / wóláchííʼ shash", "c ab", nil, nil},
+		{"mósí, wóláchííʼ shash", "c ab", nil, nil},
+		{"béésh łóóʼ naakáí", "submarine company", nil, nil},
+		{"shash béésh łóóʼ shash", "b submarine b", nil, nil},
+		{"tsídii mobba yéhé", "aircraft carrier", nil, nil},
+		// an apostrophe typed for the glottal stop
+		{"wóláchíí' shash", "ab", nil, nil},
+		{"wóláchíí’ shash", "ab", nil, nil},
+		// unknown words are passed through and reported
+		{"shash xyz mósí", "b xyz c", []Ambiguity{{1, "xyz", nil}}, []int{0}},
+		// Pos counts Navajo words, not separators or fields
+		{"shash / mósí / xyz", "b c xyz", []Ambiguity{{2, "xyz", nil}}, []int{0}},
+		{"béésh łóóʼ / xyz", "submarine xyz", []Ambiguity{{2, "xyz", nil}}, []int{0}},
+		{"shash , / xyz", "b xyz", []Ambiguity{{1, "xyz", nil}}, []int{0}},
+		// tłʼízí yázhí is k, but could be g then yázhí
+		{"shash tłʼízí yázhí", "bk", []Ambiguity{{1, "tłʼízí yázhí", nil}}, []int{1}},
+		{"", "", nil, nil},
+	} {
+		d := testTranslator(t).Decode(tt.navajo)
+		if d.Text != tt.english {
+			t.Errorf("Decode(%q).Text = %q, want %q", tt.navajo, d.Text, tt.english)
+		}
+		if len(d.Ambiguities) != len(tt.ambiguous) {
+			t.Errorf("Decode(%q).Ambiguities = %+v, want %+v", tt.navajo, d.Ambiguities, tt.ambiguous)
+			continue
+		}
+		for i, a := range d.Ambiguities {
+			want := tt.ambiguous[i]
+			if a.Pos != want.Pos || a.Navajo != want.Navajo || len(a.Options) != tt.options[i] {
+				t.Errorf("Decode(%q).Ambiguities[%d] = %+v, want %+v with %d options", tt.navajo, i, a, want, tt.options[i])
+			}
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, english := range []string{
+		"cab",
+		"a submarine",
+		"a bat at a cab",
+		"company b",
+	} {
+		tr := testTranslator(t)
+		d := tr.Decode(tr.EncodeString(english))
+		if d.Text != english {
+			t.Errorf("Decode(EncodeString(%q)) = %q", english, d.Text)
+		}
+	}
+}
+
+func TestAlternateType1Words(t *testing.T) {
+	tr := testTranslator(t)
+	// a's words are taken in turn
+	for _, want := range []string{"wóláchííʼ", "be-la-sana", "wóláchííʼ"} {
+		if got := tr.EncodeString("a"); got != want {
+			t.Errorf("EncodeString(a) = %q, want %q", got, want)
+		}
+	}
+	if got := tr.EncodeString("aa"); got != "be-la-sana wóláchííʼ" {
+		t.Errorf("EncodeString(aa) = %q, want be-la-sana wóláchííʼ", got)
+	}
+
+	// every one of them decodes to a
+	for _, nv := range []string{"wóláchííʼ", "be-la-sana"} {
+		if d := tr.Decode(nv + " shash"); d.Text != "ab" {
+			t.Errorf("Decode(%s shash) = %q, want ab", nv, d.Text)
+		}
+	}
+
+	want := []string{"wóláchííʼ", "be-la-sana"}
+	if got := tr.Spellings(CodeWord{"be-la-sana", "a", "apple", Type1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Spellings(a) = %q, want %q", got, want)
+	}
+	want = []string{"béésh łóóʼ"}
+	if got := tr.Spellings(CodeWord{"béésh łóóʼ", "submarine", "iron fish", Type2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Spellings(submarine) = %q, want %q", got, want)
+	}
+}
+
+func TestRandomise(t *testing.T) {
+	tr := testTranslator(t)
+	tr.Randomise(rand.New(rand.NewSource(1)))
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		seen[tr.EncodeString("a")] = true
+	}
+	if len(seen) != 2 || !seen["wóláchííʼ"] || !seen["be-la-sana"] {
+		t.Errorf("randomised a = %v, want both of its words", seen)
+	}
+}
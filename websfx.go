@@ -1,4 +1,4 @@
-//go:build exclude
+//go:build js && wasm
 
 package main
 
@@ -6,18 +6,16 @@ import (
 	"syscall/js"
 )
 
+// WebSfx plays a sound file through the browser, relative to the page.
 type WebSfx struct {
-	filename string
+	audio js.Value
 }
 
 func NewWebSfx(filename string) *WebSfx {
-	return &WebSfx{filename}
+	return &WebSfx{js.Global().Get("Audio").New(filename)}
 }
 
+// Play plays a copy of the sound, so it can overlap with itself.
 func (wsfx *WebSfx) Play() {
-	if wsfx.filename == "assets/ding.wav" {
-		js.Global().Call("ding")
-	} else {
-		js.Global().Call("click")
-	}
+	wsfx.audio.Call("cloneNode").Call("play")
 }
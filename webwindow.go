@@ -1,66 +1,74 @@
-//go:build exclude
+//go:build js && wasm
 
 package main
 
-import "syscall/js"
+import (
+	"syscall/js"
+	"unicode/utf8"
+)
 
+// WebWindow draws to the terminal in wnct/tcell.js. Cells are kept in
+// Go, and the ones changed since the last Show are sent to drawCell.
+//
+// tcell.js calls back into
+//
+//	onKeyEvent(key, shift, alt, ctrl, meta)
+//	onMouseMove(x, y, buttons, shift, alt, ctrl)
+//	onMouseClick(x, y, buttons, shift, alt, ctrl)
+//	onPaste(start)
+//
+// which are registered by ChannelEvents.
 type WebWindow struct {
-	changeLog map[[2]int]struct {
-		r rune
-		s string
-	}
-	doc         js.Value
+	cells       [][]pixel
+	dirty       map[[2]int]struct{}
 	DrawingRect *Rect
 	w, h        int
 	evChan      chan event
 }
 
-// TODO: implement in javascript
-// getContent(x, y) -> char + style
-// setContent(x, y, char, style)
-//
-// IF ERR: check if w.func words with js.FuncOf
-// call onMouseMove(x, y)
-// call onKeyPressed(char)
-// call onSpecialEvent(string)
+// webEventBuffer is how many events can be waiting for the run loop
+// before any more are dropped; see send.
+const webEventBuffer = 64
 
 func NewWebWindow(w, h int) *WebWindow {
-	ww := &WebWindow{make(map[[2]int]struct {
-		r rune
-		s string
-	}), js.Global().Get("document"), &Rect{0, 1, w, h - 1}, w, h, make(chan event)}
+	cells := make([][]pixel, h)
+	for y := range cells {
+		cells[y] = make([]pixel, w)
+		for x := range cells[y] {
+			cells[y][x] = pixel{' ', nil, normal.Style()}
+		}
+	}
+
+	ww := &WebWindow{cells, map[[2]int]struct{}{}, &Rect{0, 1, w, h - 1}, w, h, make(chan event, webEventBuffer)}
+	js.Global().Call("resize", w, h)
 
 	DrawOverlay(ww)
 	return ww
 }
 
-func (w *WebWindow) SetContent(x, y int, c rune, s style) {
+func (w *WebWindow) SetContent(x, y int, mainc rune, combc []rune, s Style) {
 	if !(0 <= x && x < w.w && 0 <= y && y < w.h) {
 		return
 	}
-	w.changeLog[[2]int{x, y}] = struct {
-		r rune
-		s string
-	}{c, s.String()}
+	w.cells[y][x] = pixel{mainc, combc, s}
+	w.dirty[[2]int{x, y}] = struct{}{}
 }
-func (w *WebWindow) GetContent(x, y int) (rune, style) {
-	if v, ok := w.changeLog[[2]int{x, y}]; ok {
-		return v.r, DeString(v.s)
-	}
-	v := js.Global().Call("getContent", x, y)
-	f := ' '
-	for _, c := range v.Index(0).String() {
-		f = c
-		break
+
+func (w *WebWindow) GetContent(x, y int) (rune, []rune, Style) {
+	if !(0 <= x && x < w.w && 0 <= y && y < w.h) {
+		return ' ', nil, Style{}
 	}
-	return f, DeString(v.Index(1).String())
+	px := w.cells[y][x]
+	return px.mainc, px.combc, px.s
 }
 
-// should only be called once; registers js callbacks too
+// ChannelEvents registers the tcell.js callbacks; it should only be
+// called once.
 func (w *WebWindow) ChannelEvents() (chan event, chan struct{}) {
-	js.Global().Set("onMouseMove", js.FuncOf(w.newMouseEvent))
-	js.Global().Set("onKeyPressed", js.FuncOf(w.newKeyEvent))
-	js.Global().Set("onSpecialKey", js.FuncOf(w.newSpecialEvent))
+	js.Global().Set("onKeyEvent", js.FuncOf(w.onKeyEvent))
+	js.Global().Set("onMouseMove", js.FuncOf(w.onMouse(false)))
+	js.Global().Set("onMouseClick", js.FuncOf(w.onMouse(true)))
+	js.Global().Set("onPaste", js.FuncOf(func(js.Value, []js.Value) any { return nil }))
 	return w.evChan, make(chan struct{})
 }
 
@@ -70,57 +78,112 @@ func (w *WebWindow) GetDrawingRect() *Rect {
 func (w *WebWindow) GetWidth() int  { return w.w }
 func (w *WebWindow) GetHeight() int { return w.h }
 
-func (w *WebWindow) HideCursor()         {}
-func (w *WebWindow) ShowCursor(int, int) {}
+func (w *WebWindow) HideCursor() {
+	js.Global().Call("showCursor", -1, -1)
+}
+
+func (w *WebWindow) ShowCursor(x, y int) {
+	js.Global().Call("showCursor", x, y)
+}
 
 func (w *WebWindow) Show() {
-	if len(w.changeLog) == 0 {
+	if len(w.dirty) == 0 {
 		return
 	}
-	for k, v := range w.changeLog {
-		js.Global().Call("setContent", k[0], k[1], string(v.r), v.s)
-		delete(w.changeLog, k)
+	for k := range w.dirty {
+		px := w.cells[k[1]][k[0]]
+		combc := make([]any, len(px.combc))
+		for i, c := range px.combc {
+			combc[i] = int(c)
+		}
+		js.Global().Call("drawCell", k[0], k[1], int(px.mainc), combc, jsColor(px.s.Fg.Hex()), jsColor(px.s.Bg.Hex()), int(px.s.Attrs))
+		delete(w.dirty, k)
 	}
 	js.Global().Call("show")
 }
-func (w *WebWindow) Fini() {}
+
+// jsColor is a colour for drawCell; null leaves the terminal's own.
+func jsColor(hex int32) any {
+	if hex < 0 {
+		return js.Null()
+	}
+	return int(hex)
+}
+
+func (w *WebWindow) Fini() {
+	js.Global().Call("clearScreen")
+	js.Global().Call("show")
+}
+
 func (w *WebWindow) Sync() {}
 
-func (w *WebWindow) newMouseEvent(this js.Value, args []js.Value) any {
-	w.evChan <- &mouseEvent{args[0].Int(), args[1].Int()}
-	return nil
+func (w *WebWindow) onMouse(click bool) func(js.Value, []js.Value) any {
+	return func(this js.Value, args []js.Value) any {
+		x, y := args[0].Int(), args[1].Int()
+		w.send(&mouseEvent{x, y, click})
+		if click { // a click comes once it's let go
+			w.send(&mouseEvent{x, y, false})
+		}
+		return nil
+	}
 }
 
-func (w *WebWindow) newKeyEvent(this js.Value, args []js.Value) any {
-	f := ' '
-	for _, c := range args[0].String() {
-		f = c
-		break
+// onKeyEvent turns a KeyboardEvent.key into an event. Keys with no
+// meaning to the tutorial, like Shift on its own, are dropped.
+func (w *WebWindow) onKeyEvent(this js.Value, args []js.Value) any {
+	key, ctrl := args[0].String(), args[3].Bool()
+
+	var e event
+	switch key {
+	case "ArrowUp":
+		e = &specialEvent{up}
+	case "ArrowDown":
+		e = &specialEvent{down}
+	case "ArrowLeft":
+		e = &specialEvent{left}
+	case "ArrowRight":
+		e = &specialEvent{right}
+	case "Backspace":
+		e = &specialEvent{backspace}
+	case "Enter":
+		e = &specialEvent{enter}
+	case "Delete":
+		e = &specialEvent{del}
+	case "Home":
+		e = &specialEvent{home}
+	case "End":
+		e = &specialEvent{end}
+	case "Escape":
+		e = &specialEvent{reset}
+	default:
+		r, size := utf8.DecodeRuneInString(key)
+		if size != len(key) { // a named key like "Shift"
+			return nil
+		}
+		if ctrl {
+			switch r {
+			case 'c':
+				e = &specialEvent{quit}
+			case 'w':
+				e = &specialEvent{deleteWord}
+			}
+			break
+		}
+		e = &keyEvent{r}
+	}
+
+	if e != nil {
+		w.send(e)
 	}
-	w.evChan <- &keyEvent{f}
 	return nil
 }
 
-func (w *WebWindow) newSpecialEvent(this js.Value, args []js.Value) any {
-	var sk specialKey
-	switch args[0].String() {
-	case "up":
-		sk = up
-	case "down":
-		sk = down
-	case "left":
-		sk = left
-	case "right":
-		sk = right
-	case "backspace":
-		sk = backspace
-	case "enter":
-		sk = enter
-	case "quit":
-		sk = quit
-	case "reset":
-		sk = reset
+// send queues e for the run loop. The callbacks run on the page's only
+// thread, so rather than block it, and the run loop with it, e is
+// dropped if the loop is a whole buffer behind.
+func (w *WebWindow) send(e event) {
+	select {
+	case w.evChan <- e:
+	default:
 	}
-	w.evChan <- &specialEvent{sk}
-	return nil
 }
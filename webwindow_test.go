@@ -0,0 +1,18 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestWebWindowSendDrops(t *testing.T) {
+	w := &WebWindow{evChan: make(chan event, 2)}
+	for i := 0; i < 5; i++ {
+		w.send(&keyEvent{rune('a' + i)})
+	}
+	if len(w.evChan) != 2 {
+		t.Fatalf("%d events queued, want 2", len(w.evChan))
+	}
+	if e := <-w.evChan; e.(*keyEvent).Rune() != 'a' {
+		t.Errorf("first event %v, want the first sent", e)
+	}
+}
@@ -1,8 +1,16 @@
 package main
 
+import (
+	"fmt"
+
+	"github.com/Ahoys123/tcell"
+)
+
 type Window interface {
-	SetContent(int, int, rune, style)
-	GetContent(int, int) (rune, style)
+	// SetContent draws mainc at x, y with the combining runes combc on
+	// it, as tcell does. GetContent gives back exactly what was drawn.
+	SetContent(x, y int, mainc rune, combc []rune, s Style)
+	GetContent(x, y int) (mainc rune, combc []rune, s Style)
 	ChannelEvents() (evChan chan event, quit chan struct{})
 
 	GetDrawingRect() *Rect
@@ -17,20 +25,21 @@ type Window interface {
 	Sync()
 }
 
-func DrawBoxAround(r *Rect, s style, w Window) {
+func DrawBoxAround(r *Rect, st style, w Window) {
+	s := st.Style()
 
-	w.SetContent(r.x-1, r.y-1, '•', s)
-	w.SetContent(r.x+r.w, r.y-1, '•', s)
-	w.SetContent(r.x-1, r.y+r.h, '•', s)
-	w.SetContent(r.x+r.w, r.y+r.h, '•', s)
+	w.SetContent(r.x-1, r.y-1, '•', nil, s)
+	w.SetContent(r.x+r.w, r.y-1, '•', nil, s)
+	w.SetContent(r.x-1, r.y+r.h, '•', nil, s)
+	w.SetContent(r.x+r.w, r.y+r.h, '•', nil, s)
 
 	for x := r.x; x < r.x+r.w; x++ {
-		w.SetContent(x, r.y-1, '-', s)
-		w.SetContent(x, r.y+r.h, '-', s)
+		w.SetContent(x, r.y-1, '-', nil, s)
+		w.SetContent(x, r.y+r.h, '-', nil, s)
 	}
 	for y := r.y; y < r.y+r.h; y++ {
-		w.SetContent(r.x-1, y, '|', s)
-		w.SetContent(r.x+r.w, y, '|', s)
+		w.SetContent(r.x-1, y, '|', nil, s)
+		w.SetContent(r.x+r.w, y, '|', nil, s)
 	}
 }
 
@@ -43,41 +52,16 @@ func MarginRect(x, y, height int, w Window) *Rect {
 	return &Rect{dr.x + x + 1, dr.y + y + 1, dr.w - 1 - (dr.x + x + 1), height}
 }
 
+// DrawTextOffset draws text in r as if offset cells of it had been
+// drawn already, wrapping at the edge of r and stopping at its bottom.
 func DrawTextOffset(text string, r *Rect, offset int, s style, w Window) {
-	textRune := []rune(text)
-	curChar := 0
-
-	for curChar < len(textRune) {
-
-		x := (curChar + offset) % r.w
-		// set next cell's content
-		if textRune[curChar] == '\n' {
-			if x != 0 {
-				offset += r.w - x
-			}
-			offset -= 1
-			curChar++
-			continue
-		}
-		if textRune[curChar] == '\t' {
-			offset += 4
-			curChar++
-			continue
-		}
-		y := (curChar + offset) / r.w
-		if y >= r.h {
-			return
-		}
-
-		w.SetContent(r.x+x, r.y+y, textRune[curChar], s)
-		curChar++
-	}
+	layoutText(text, r.w, offset).draw(r, s.Style(), w)
 }
 
 func FillRect(with rune, r *Rect, w Window) {
 	for x := r.x; x < r.x+r.w; x++ {
 		for y := r.y; y < r.y+r.h; y++ {
-			w.SetContent(x, y, with, normal)
+			w.SetContent(x, y, with, nil, normal.Style())
 		}
 	}
 }
@@ -87,6 +71,13 @@ func DrawOverlay(w Window) {
 	DrawText("[ESCAPE] to title", &Rect{w.GetWidth() - 17, 0, 17, 1}, option, w)
 }
 
+// DrawResolutionWarning asks for the window to be made at least width
+// by height.
+func DrawResolutionWarning(width, height int, w Window) {
+	msg := fmt.Sprintf("%dx%d is min size.\nPlease expand your terminal.", width, height)
+	DrawText(msg, &Rect{0, 0, w.GetWidth(), w.GetHeight()}, normal, w)
+}
+
 func DrawDebug(text string, y int, w Window) {
 	DrawText(text, &Rect{1, y, 100, 10}, normal, w)
 }
@@ -102,8 +93,43 @@ const (
 	t1en
 	t1ne
 	t2ne
+	mistake
 )
 
+// Style is how s is drawn in the current theme.
+func (s style) Style() Style {
+	return theme.Style(s)
+}
+
+// Style is how a cell is drawn: its foreground and background colours,
+// tcell.ColorDefault for the terminal's own, and attributes such as
+// bold. The zero Style is the terminal's own.
+type Style struct {
+	Fg, Bg tcell.Color
+	Attrs  tcell.AttrMask
+}
+
+func (s Style) Bold() Style      { return s.with(tcell.AttrBold) }
+func (s Style) Italic() Style    { return s.with(tcell.AttrItalic) }
+func (s Style) Underline() Style { return s.with(tcell.AttrUnderline) }
+func (s Style) Reverse() Style   { return s.with(tcell.AttrReverse) }
+
+func (s Style) with(attrs tcell.AttrMask) Style {
+	s.Attrs |= attrs
+	return s
+}
+
+// tcell is s as a tcell.Style, for the terminal to draw.
+func (s Style) tcell() tcell.Style {
+	return tcell.StyleDefault.Foreground(s.Fg).Background(s.Bg).Attributes(s.Attrs)
+}
+
+// tcellToStyle is the Style of a tcell.Style.
+func tcellToStyle(ts tcell.Style) Style {
+	fg, bg, attrs := ts.Decompose()
+	return Style{fg, bg, attrs}
+}
+
 func (s style) String() string {
 	switch s {
 	case normal:
@@ -122,6 +148,8 @@ func (s style) String() string {
 		return "t1ne"
 	case t2ne:
 		return "t2ne"
+	case mistake:
+		return "mistake"
 	}
 	return "unknown"
 }
@@ -144,6 +172,8 @@ func DeString(str string) style {
 		return t1ne
 	case "t2ne":
 		return t2ne
+	case "mistake":
+		return mistake
 	}
 	return normal
 }
@@ -56,8 +56,8 @@ function clearScreen(fg, bg) {
 }
 
 function drawCell(x, y, mainc, combc, fg, bg, attrs) {
-    var combString = String.fromCharCode(mainc)
-    combc.forEach(char => {combString += String.fromCharCode(char)});
+    var combString = String.fromCodePoint(mainc)
+    combc.forEach(char => {combString += String.fromCodePoint(char)});
 
     var span = document.createElement("span")
     var use = false
@@ -167,19 +167,23 @@ function intToHex(n) {
 
 initialize()
 
-let fontwidth = term.clientWidth / width
-let fontheight = term.clientHeight / height
+// the cell size changes whenever resize is called, so work it out per event
+function cellAt(e) {
+    let fontwidth = term.clientWidth / width
+    let fontheight = term.clientHeight / height
+    return [Math.min((e.offsetX / fontwidth) | 0, width-1), Math.min((e.offsetY / fontheight) | 0, height-1)]
+}
 
 document.addEventListener("keydown", e => {
     onKeyEvent(e.key, e.shiftKey, e.altKey, e.ctrlKey, e.metaKey)
 })
 
 term.addEventListener("click", e => {
-    onMouseClick(Math.min((e.offsetX / fontwidth) | 0, width-1), Math.min((e.offsetY / fontheight) | 0, height-1), e.which, e.shiftKey, e.altKey, e.ctrlKey)
+    onMouseClick(...cellAt(e), e.which, e.shiftKey, e.altKey, e.ctrlKey)
 })
 
 term.addEventListener("mousemove", e => {
-    onMouseMove(Math.min((e.offsetX / fontwidth) | 0, width-1), Math.min((e.offsetY / fontheight) | 0, height-1), e.which, e.shiftKey, e.altKey, e.ctrlKey)
+    onMouseMove(...cellAt(e), e.which, e.shiftKey, e.altKey, e.ctrlKey)
 })
 
 document.addEventListener("paste", e => {